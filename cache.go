@@ -0,0 +1,311 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	_ ConfigProvider        = &cacheProvider{}
+	_ ContextConfigProvider = &cacheProvider{}
+	_ SourceNamer           = &cacheProvider{}
+	_ FormatHinter          = &cacheProvider{}
+)
+
+type CacheOption func(*cacheOpts) error
+
+type cacheOpts struct {
+	maxAge   time.Duration
+	timeout  time.Duration
+	notFound bool
+}
+
+// CacheWithMaxAge sets the maximum age of cached data that can be served.
+// By default, the age isn't limited.
+func CacheWithMaxAge(d time.Duration) CacheOption {
+	return func(v *cacheOpts) error {
+		if d <= 0 {
+			return fmt.Errorf("max age must be positive, got %s", d)
+		}
+
+		v.maxAge = d
+		return nil
+	}
+}
+
+// CacheWithTimeout limits the time of a call of the upstream provider, so cached data is
+// served if the upstream hangs. By default, the time isn't limited.
+func CacheWithTimeout(d time.Duration) CacheOption {
+	return func(v *cacheOpts) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive, got %s", d)
+		}
+
+		v.timeout = d
+		return nil
+	}
+}
+
+// CacheWithNotFoundFallback makes the provider serve cached data also when the upstream
+// source doesn't exist (see ErrSourceNotFound). By default, a deleted source isn't masked
+// by the cache and its error is returned.
+func CacheWithNotFoundFallback() CacheOption {
+	return func(v *cacheOpts) error {
+		v.notFound = true
+		return nil
+	}
+}
+
+// CacheStatus describes data provided by the last call of a cache provider.
+type CacheStatus struct {
+	// Stale is true if the data was served from the cache, because the upstream provider failed.
+	Stale bool
+	// CachedAt is the time when served data was cached. Zero if the data is fresh.
+	CachedAt time.Time
+	// Err is the error of the upstream provider if the data is stale.
+	Err error
+	// CacheErr is the error of writing fresh data to the cache. It doesn't fail creating config.
+	CacheErr error
+}
+
+type cacheProvider struct {
+	provider  ConfigProvider
+	cachePath string
+	funcOpts  []CacheOption
+
+	mu     sync.Mutex
+	status CacheStatus
+}
+
+// WithCache returns provider that keeps the last known good data of provider in the file
+// cachePath and serves it when provider fails, e.g. when Consul is down at startup.
+//
+// Data is cached only after the config built from it is accepted: decoded, with references
+// resolved and validated, so invalid data doesn't replace good one. Cached data isn't served
+// if the upstream source doesn't exist, unless CacheWithNotFoundFallback is passed.
+// The cache file is written atomically and holds a checksum of the data, corrupted cache
+// isn't served. Use Status to check whether the last provided data is stale:
+//
+//	provider := config.WithCache(config.FromConsul("service/config"), "/var/cache/service/config.cache",
+//		config.CacheWithTimeout(5*time.Second),
+//		config.CacheWithMaxAge(7*24*time.Hour),
+//	)
+//
+//	cfg, err := config.New[Config](provider)
+//	// ...
+//	if status := provider.Status(); status.Stale {
+//		log.Printf("config is stale since %s: %v", status.CachedAt, status.Err)
+//	}
+func WithCache(provider ConfigProvider, cachePath string, opts ...CacheOption) *cacheProvider {
+	return &cacheProvider{
+		provider:  provider,
+		cachePath: cachePath,
+		funcOpts:  opts,
+	}
+}
+
+// Status returns the status of data provided by the last call.
+func (c *cacheProvider) Status() CacheStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.status
+}
+
+// ProvideConfig implements ConfigProvider.
+func (c *cacheProvider) ProvideConfig() (io.Reader, error) {
+	return c.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is propagated to the upstream
+// provider.
+func (c *cacheProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	var opts cacheOpts
+	for _, opt := range c.funcOpts {
+		if opt == nil {
+			continue
+		}
+
+		if err := opt(&opts); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	upstreamCtx := ctx
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		upstreamCtx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	r, err := provideConfig(upstreamCtx, c.provider)
+	if err == nil {
+		// Data is read at once, so reading isn't interrupted by the timeout.
+		format := formatHint(r)
+		data, err := readAllClose(r)
+		if err != nil {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+
+		c.setStatus(CacheStatus{})
+		return &cacheReader{Reader: bytes.NewReader(data), cache: c, data: data, format: format}, nil
+	}
+
+	if ctx.Err() != nil || (isSourceNotFound(err) && !opts.notFound) {
+		return nil, err
+	}
+
+	entry, cacheErr := c.load()
+	switch {
+	case errors.Is(cacheErr, fs.ErrNotExist):
+		return nil, err
+	case cacheErr != nil:
+		return nil, errors.Join(err, fmt.Errorf("read cache: %v", cacheErr))
+	case opts.maxAge > 0 && time.Since(entry.CachedAt) > opts.maxAge:
+		return nil, errors.Join(err, fmt.Errorf("cache is older than %s", opts.maxAge))
+	}
+
+	c.setStatus(CacheStatus{Stale: true, CachedAt: entry.CachedAt, Err: err})
+	return bytes.NewReader(entry.Data), nil
+}
+
+func (c *cacheProvider) setStatus(status CacheStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.status = status
+}
+
+// Source implements SourceNamer. It returns the name of the upstream provider.
+func (c *cacheProvider) Source() string {
+	return sourceName(c.provider)
+}
+
+// FormatHint implements FormatHinter. It returns the hint of the upstream provider.
+func (c *cacheProvider) FormatHint() string {
+	if hinter, ok := c.provider.(FormatHinter); ok {
+		return hinter.FormatHint()
+	}
+
+	return ""
+}
+
+// cacheEntry is the content of the cache file.
+type cacheEntry struct {
+	CachedAt time.Time `json:"cached_at"`
+	Checksum string    `json:"checksum"`
+	Data     []byte    `json:"data"`
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (c *cacheProvider) load() (cacheEntry, error) {
+	raw, err := os.ReadFile(c.cachePath)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return cacheEntry{}, fmt.Errorf("decode cache file: %w", err)
+	}
+
+	if checksum(entry.Data) != entry.Checksum {
+		return cacheEntry{}, errors.New("checksum mismatch")
+	}
+
+	return entry, nil
+}
+
+// store writes data to the cache file atomically: data is written to a temporary file
+// in the same directory, which then replaces the cache file.
+func (c *cacheProvider) store(data []byte) (err error) {
+	raw, err := json.Marshal(cacheEntry{
+		CachedAt: time.Now(),
+		Checksum: checksum(data),
+		Data:     data,
+	})
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(c.cachePath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(c.cachePath)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.cachePath)
+}
+
+// decodedCommitter is implemented by readers that need to know that the config built from
+// their data was accepted (see decodeConfigs).
+type decodedCommitter interface {
+	commitDecoded()
+}
+
+// cacheReader is a reader of fresh data that caches the data when the config is accepted.
+type cacheReader struct {
+	*bytes.Reader
+	cache  *cacheProvider
+	data   []byte
+	format string
+}
+
+// FormatHint implements FormatHinter. It returns the hint of the upstream reader.
+func (r *cacheReader) FormatHint() string {
+	return r.format
+}
+
+func (r *cacheReader) commitDecoded() {
+	if err := r.cache.store(r.data); err != nil {
+		r.cache.setStatus(CacheStatus{CacheErr: fmt.Errorf("write cache: %w", err)})
+	}
+}
+
+// readAllClose reads all data from r and closes it if it implements the io.Closer interface.
+func readAllClose(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if c, ok := r.(io.Closer); ok {
+		if closeErr := c.Close(); closeErr != nil && !errors.Is(closeErr, os.ErrClosed) {
+			err = errors.Join(err, closeErr)
+		}
+	}
+
+	return data, err
+}
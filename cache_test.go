@@ -0,0 +1,188 @@
+package config_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+// switchProvider provides data or fails with err.
+type switchProvider struct {
+	mu   sync.Mutex
+	data string
+	err  error
+}
+
+func (p *switchProvider) set(data string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.data, p.err = data, err
+}
+
+func (p *switchProvider) ProvideConfig() (io.Reader, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return strings.NewReader(p.data), nil
+}
+
+func TestWithCache(t *testing.T) {
+	errDown := errors.New("consul is down")
+
+	t.Run("serves last known good", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "cache", "config.cache")
+		upstream := &switchProvider{data: `{"foo": "good"}`}
+		provider := config.WithCache(upstream, cachePath)
+
+		cfg, err := config.New[testConfig](provider)
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "good"}, cfg)
+		require.Equal(t, config.CacheStatus{}, provider.Status())
+		require.FileExists(t, cachePath)
+
+		upstream.set(`{"foo": `, nil)
+		_, err = config.New[testConfig](provider)
+		require.Error(t, err, "invalid data isn't cached")
+
+		upstream.set("", errDown)
+		cfg, err = config.New[testConfig](provider)
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "good"}, cfg)
+
+		status := provider.Status()
+		require.True(t, status.Stale)
+		require.WithinDuration(t, time.Now(), status.CachedAt, time.Minute)
+		require.ErrorIs(t, status.Err, errDown)
+
+		upstream.set(`{"foo": "new"}`, nil)
+		cfg, err = config.New[testConfig](provider)
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "new"}, cfg)
+		require.False(t, provider.Status().Stale)
+	})
+
+	t.Run("rejected data isn't cached", func(t *testing.T) {
+		type validatedConfig struct {
+			Foo string `json:"foo" validate:"required"`
+			Ref string `json:"ref"`
+		}
+
+		cachePath := filepath.Join(t.TempDir(), "config.cache")
+		upstream := &switchProvider{data: `{"foo": "good"}`}
+		provider := config.WithCache(upstream, cachePath)
+
+		_, err := config.New[validatedConfig](provider)
+		require.NoError(t, err)
+
+		upstream.set(`{"foo": ""}`, nil)
+		_, err = config.New[validatedConfig](provider)
+		require.ErrorContains(t, err, "violates rule 'required'")
+
+		upstream.set(`{"foo": "bar", "ref": "${env:TEST_CACHE_MISSING}"}`, nil)
+		_, err = config.New[validatedConfig](provider, config.WithReferences())
+		require.Error(t, err)
+
+		upstream.set(`{"foo": ""}`, nil)
+		_, err = config.Multi[validatedConfig]().Add(config.FromReader(strings.NewReader(`{"ref": "x"}`))).Add(provider).AllOf()
+		require.ErrorContains(t, err, "violates rule 'required'")
+
+		upstream.set("", errDown)
+		cfg, err := config.New[validatedConfig](provider)
+		require.NoError(t, err)
+		require.Equal(t, "good", cfg.Foo)
+	})
+
+	t.Run("deleted source", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "config.cache")
+		upstream := &switchProvider{data: `{"foo": "good"}`}
+
+		_, err := config.New[testConfig](config.WithCache(upstream, cachePath))
+		require.NoError(t, err)
+
+		upstream.set("", config.ErrSourceNotFound)
+		_, err = config.New[testConfig](config.WithCache(upstream, cachePath))
+		require.ErrorIs(t, err, config.ErrSourceNotFound)
+
+		cfg, err := config.New[testConfig](config.WithCache(upstream, cachePath, config.CacheWithNotFoundFallback()))
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "good"}, cfg)
+	})
+
+	t.Run("no cache", func(t *testing.T) {
+		provider := config.WithCache(&switchProvider{err: errDown}, filepath.Join(t.TempDir(), "config.cache"))
+
+		_, err := config.New[testConfig](provider)
+		require.ErrorIs(t, err, errDown)
+		require.NotErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("corrupted cache", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "config.cache")
+		upstream := &switchProvider{data: `{"foo": "good"}`}
+		provider := config.WithCache(upstream, cachePath)
+
+		_, err := config.New[testConfig](provider)
+		require.NoError(t, err)
+
+		raw, err := os.ReadFile(cachePath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(cachePath, []byte(strings.Replace(string(raw), `"data":"`, `"data":"AAAA`, 1)), 0o644))
+
+		upstream.set("", errDown)
+		_, err = config.New[testConfig](provider)
+		require.ErrorIs(t, err, errDown)
+		require.ErrorContains(t, err, "checksum mismatch")
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "config.cache")
+		upstream := &switchProvider{data: `{"foo": "good"}`}
+		provider := config.WithCache(upstream, cachePath, config.CacheWithMaxAge(time.Millisecond))
+
+		_, err := config.New[testConfig](provider)
+		require.NoError(t, err)
+
+		time.Sleep(5 * time.Millisecond)
+		upstream.set("", errDown)
+		_, err = config.New[testConfig](provider)
+		require.ErrorIs(t, err, errDown)
+		require.ErrorContains(t, err, "cache is older than 1ms")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "config.cache")
+		_, err := config.New[testConfig](config.WithCache(okProvider(), cachePath))
+		require.NoError(t, err)
+
+		hung := &hungProvider{release: make(chan struct{})}
+		defer close(hung.release)
+
+		provider := config.WithCache(hung, cachePath, config.CacheWithTimeout(20*time.Millisecond))
+		cfg, err := config.New[testConfig](provider)
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "bar"}, cfg)
+		require.True(t, provider.Status().Stale)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := config.New[testConfig](config.WithCache(okProvider(), filepath.Join(t.TempDir(), "c"), config.CacheWithMaxAge(0)))
+		require.Error(t, err)
+
+		// Nil options are skipped.
+		_, err = config.New[testConfig](config.WithCache(okProvider(), filepath.Join(t.TempDir(), "c"), nil))
+		require.NoError(t, err)
+	})
+}
@@ -14,11 +14,17 @@ const cmdSep = " "
 
 var (
 	_ ConfigProvider = &cmdlineProvider{}
+	_ SourceNamer    = &cmdlineProvider{}
 	_ Decoder        = &cmdlineDecoder{}
 )
 
 type cmdlineProvider struct{}
 
+// Source implements SourceNamer.
+func (*cmdlineProvider) Source() string {
+	return "cmdline"
+}
+
 // ProvideConfig implements ConfigProvider.
 func (*cmdlineProvider) ProvideConfig() (io.Reader, error) {
 	return strings.NewReader(strings.Join(os.Args[1:], cmdSep)), nil
@@ -43,6 +49,8 @@ func (d *cmdlineDecoder) Decode(v any) error {
 	args := strings.Split(string(b), cmdSep)
 
 	p := flags.NewParser(v, flags.HelpFlag|flags.PassDoubleDash)
+	disableFlagDefaults(p.Command)
+
 	_, err = p.ParseArgs(args)
 	if isErrHelp(err) {
 		fmt.Fprintln(os.Stdout, err)
@@ -63,6 +71,28 @@ func CmdlineDecoder(r io.Reader) *cmdlineDecoder {
 	return &cmdlineDecoder{r: r}
 }
 
+// disableFlagDefaults prevents go-flags from resetting options that weren't passed to
+// their `default` tag values, because defaults are already set by New and Multi before
+// decoding. Otherwise, values decoded from the previous sources would be lost.
+// Help still shows the current values as defaults.
+func disableFlagDefaults(cmd *flags.Command) {
+	var disable func(g *flags.Group)
+	disable = func(g *flags.Group) {
+		for _, opt := range g.Options() {
+			opt.Default = nil
+		}
+
+		for _, sub := range g.Groups() {
+			disable(sub)
+		}
+	}
+
+	disable(cmd.Group)
+	for _, sub := range cmd.Commands() {
+		disableFlagDefaults(sub)
+	}
+}
+
 func isErrHelp(err error) bool {
 	if err == nil {
 		return false
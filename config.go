@@ -1,11 +1,14 @@
 package config
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"time"
 
 	"github.com/MordaTeam/go-toolbox/options"
 )
@@ -22,10 +25,33 @@ type Decoder interface {
 }
 
 type cfgOpts struct {
-	newDec func(r io.Reader) Decoder
+	newDec        func(r io.Reader) Decoder
+	autoDecoder   bool
+	strict        bool
+	watchInterval time.Duration
+
+	resolvers      map[string]Resolver
+	referenceDepth int
 }
 
-// WithDecoder is an option that overrides the default decoder. By default, it uses json.Decoder.
+func newCfgOpts(opts ...options.Option[cfgOpts]) (cfgOpts, error) {
+	cfgOpts := cfgOpts{
+		newDec: DecoderWrap(JsonDecoder),
+	}
+
+	for _, option := range opts {
+		if option == nil {
+			continue
+		}
+		if err := option(&cfgOpts); err != nil {
+			return cfgOpts, fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	return cfgOpts, nil
+}
+
+// WithDecoder is an option that overrides the default decoder. By default, it uses JsonDecoder.
 func WithDecoder[D Decoder](newDec func(r io.Reader) D) options.Option[cfgOpts] {
 	return func(v *cfgOpts) error {
 		v.newDec = func(r io.Reader) Decoder {
@@ -35,13 +61,84 @@ func WithDecoder[D Decoder](newDec func(r io.Reader) D) options.Option[cfgOpts]
 	}
 }
 
-// Creates config T where provider provides data for decoding. By default, it uses json.Decoder.
+// Creates config T where provider provides data for decoding. By default, it uses JsonDecoder.
 // Use WithDecoder to override the decoder. If the reader implements the io.Closer interface, then
 // it will be closed.
+//
+// Before decoding, fields are set to values from `default` tag, so decoders override only
+// provided values:
+//
+//	type Config struct {
+//		Timeout time.Duration `json:"timeout" default:"5s"`
+//		Hosts   []string      `json:"hosts" default:"localhost,127.0.0.1"`
+//	}
+//
+// After decoding, config is validated by `validate` tags and by Validate method if T or *T
+// implements Validator:
+//
+//	type Config struct {
+//		Host string `json:"host" validate:"required"`
+//		Mode string `json:"mode" validate:"oneof=dev|prod"`
+//	}
 func New[T any](provider ConfigProvider, opts ...options.Option[cfgOpts]) (cfg T, err error) {
-	r, err := provider.ProvideConfig()
+	return NewContext[T](context.Background(), provider, opts...)
+}
+
+// NewContext creates config T like New. Cancellation and deadline of ctx are propagated
+// to providers that implement ContextConfigProvider and to reading of provided data.
+// Other providers are abandoned when ctx is done, so a hung provider doesn't block the call:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//
+//	cfg, err := config.NewContext[Config](ctx, config.FromConsul("service/config"))
+func NewContext[T any](ctx context.Context, provider ConfigProvider, opts ...options.Option[cfgOpts]) (cfg T, err error) {
+	cfg, commit, err := newConfig[T](ctx, provider, opts...)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := validateConfig(&cfg); err != nil {
+		return cfg, err
+	}
+
+	commit()
+	return cfg, nil
+}
+
+// newConfig creates config T like New, but doesn't validate it.
+// Commit must be called when the config is accepted, see decodeConfigs.
+func newConfig[T any](ctx context.Context, provider ConfigProvider, opts ...options.Option[cfgOpts]) (cfg T, commit func(), err error) {
+	cfgOpts, err := newCfgOpts(opts...)
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	if err := setDefaults(&cfg); err != nil {
+		return cfg, nil, fmt.Errorf("apply defaults: %w", err)
+	}
+
+	commit, err = decodeConfigs(ctx, provider, cfgOpts, &cfg)
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	if err := cfgOpts.resolveReferences(ctx, &cfg); err != nil {
+		return cfg, nil, err
+	}
+
+	return cfg, commit, nil
+}
+
+// decodeConfigs decodes data provided by provider into each of cfgs.
+// If there are several configs, the data is read once and decoded from memory.
+//
+// Commit must be called when the config built from the data is accepted (e.g. validated),
+// so providers like WithCache keep only good data.
+func decodeConfigs(ctx context.Context, provider ConfigProvider, cfgOpts cfgOpts, cfgs ...any) (commit func(), err error) {
+	r, err := provideConfig(ctx, provider)
 	if err != nil {
-		return cfg, fmt.Errorf("provide config: %w", err)
+		return nil, newProviderError(provider, err)
 	}
 
 	defer func() {
@@ -52,37 +149,58 @@ func New[T any](provider ConfigProvider, opts ...options.Option[cfgOpts]) (cfg T
 
 		closeErr := r.Close()
 		if closeErr != nil && !errors.Is(closeErr, os.ErrClosed) {
-			closeErr = fmt.Errorf("close reader: %w", closeErr)
+			err = errors.Join(err, fmt.Errorf("close reader: %w", closeErr))
 		}
-
-		err = errors.Join(err, closeErr)
 	}()
 
-	cfgOpts := cfgOpts{
-		newDec: func(r io.Reader) Decoder {
-			return json.NewDecoder(r)
-		},
+	// Decoder is chosen after providing, so providers can hint the format of provided data.
+	newDec, err := cfgOpts.decoderFor(provider, r)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, option := range opts {
-		if option == nil {
-			continue
+	var (
+		data []byte
+		read bytes.Buffer
+		src  io.Reader
+	)
+	if len(cfgs) > 1 {
+		if data, err = io.ReadAll(r); err != nil {
+			return nil, newProviderError(provider, fmt.Errorf("read config: %w", err))
 		}
-		if err := option(&cfgOpts); err != nil {
-			return cfg, fmt.Errorf("apply option: %w", err)
+	} else {
+		// Read data is kept to find the position of a decoding error.
+		src = io.TeeReader(r, &read)
+	}
+
+	for _, cfg := range cfgs {
+		if data != nil {
+			src = bytes.NewReader(data)
+		}
+
+		dec := newDec(src)
+		if strictDec, ok := dec.(strictDecoder); ok && cfgOpts.strict {
+			strictDec.DisallowUnknownFields()
+		}
+
+		if err := dec.Decode(cfg); err != nil {
+			if data == nil {
+				data = read.Bytes()
+			}
+			return nil, newDecodeError(sourceName(provider), data, err)
 		}
 	}
 
-	if err := cfgOpts.newDec(r).Decode(&cfg); err != nil {
-		return cfg, fmt.Errorf("decode config: %w", err)
+	if c, ok := r.(decodedCommitter); ok {
+		return c.commitDecoded, nil
 	}
 
-	return cfg, nil
+	return func() {}, nil
 }
 
 // Fill creates config and fills into cfg argument.
 //
-// Provider provides data for decoding. By default, it uses decoder json.Decoder.
+// Provider provides data for decoding. By default, it uses decoder JsonDecoder.
 // Use WithDecoder to override the decoder. If the reader implements the io.Closer interface, then
 // it will be closed.
 //
@@ -100,12 +218,46 @@ func New[T any](provider ConfigProvider, opts ...options.Option[cfgOpts]) (cfg T
 //		err := config.Fill(&cfg, config.FromConsul"/bar/foo"))
 //		//...
 //	}
+//
+// Fields present in provided data override values of cfg, even if they are zero (e.g. false
+// or 0). Other fields keep values of cfg, zero fields of cfg are set from `default` tag.
+// Structs, maps and pointers to structs are merged deeply, use `merge` tag to change
+// the strategy of a field:
+//
+//	type Config struct {
+//		Hosts  []string          `json:"hosts" merge:"append"`  // items of cfg, then provided
+//		Tags   []string          `json:"tags" merge:"union"`    // like append, without duplicates
+//		Labels map[string]string `json:"labels" merge:"replace"` // whole map from one source
+//	}
+//
+// The result of merging is validated like in New.
 func Fill[T any](cfg *T, provider ConfigProvider, opts ...options.Option[cfgOpts]) error {
-	newCfg, err := New[T](provider, opts...)
+	return FillContext(context.Background(), cfg, provider, opts...)
+}
+
+// FillContext fills cfg like Fill. Cancellation and deadline of ctx are propagated to
+// the provider like in NewContext.
+func FillContext[T any](ctx context.Context, cfg *T, provider ConfigProvider, opts ...options.Option[cfgOpts]) error {
+	if err := checkMergeTags(reflect.TypeOf(cfg).Elem()); err != nil {
+		return fmt.Errorf("merge config: %w", err)
+	}
+
+	var defaults T
+	if err := setDefaults(&defaults); err != nil {
+		return fmt.Errorf("create config: apply defaults: %w", err)
+	}
+
+	src, present, commit, err := newSource[T](ctx, provider, opts...)
 	if err != nil {
 		return fmt.Errorf("create config: %w", err)
 	}
 
-	*cfg = mergeLeft(*cfg, newCfg)
+	merged := mergeSource(mergeLeft(*cfg, defaults), src, present)
+	if err := validateConfig(&merged); err != nil {
+		return err
+	}
+
+	*cfg = merged
+	commit()
 	return nil
 }
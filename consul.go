@@ -2,35 +2,66 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 )
 
-var _ ConfigProvider = &consulProvider{}
+const (
+	// DefaultConsulWaitTime is the default maximum duration of a blocking query used by Watch.
+	DefaultConsulWaitTime = 5 * time.Minute
+
+	defaultConsulMinBackoff = time.Second
+	defaultConsulMaxBackoff = time.Minute
+)
+
+var (
+	_ ConfigProvider        = &consulProvider{}
+	_ ContextConfigProvider = &consulProvider{}
+	_ WatchableProvider     = &consulProvider{}
+	_ SourceNamer           = &consulProvider{}
+)
 
 type ConsulOption func(*consulOpts) error
 
 type consulOpts struct {
-	client  *consul.Client
-	qryOpts *consul.QueryOptions
+	client     *consul.Client
+	qryOpts    *consul.QueryOptions
+	waitTime   time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	typedValues  bool
+	keyTransform func(segment string) string
 }
 
-type consulProvider struct {
+// consulBase holds consul client and options shared by consul providers.
+type consulBase struct {
 	client   *consul.Client
 	qryOpts  *consul.QueryOptions
-	cfgPath  string
+	opts     consulOpts
 	funcOpts []ConsulOption
 }
 
-func (c *consulProvider) lazyInit() error {
+type consulProvider struct {
+	consulBase
+	cfgPath string
+}
+
+func (c *consulBase) lazyInit() error {
 	if c.client != nil {
 		return nil
 	}
 
-	var consulOpts consulOpts
+	consulOpts := consulOpts{
+		waitTime:   DefaultConsulWaitTime,
+		minBackoff: defaultConsulMinBackoff,
+		maxBackoff: defaultConsulMaxBackoff,
+	}
 	for _, option := range c.funcOpts {
 		if option == nil {
 			continue
@@ -52,33 +83,154 @@ func (c *consulProvider) lazyInit() error {
 	}
 
 	c.client = consulOpts.client
+	c.qryOpts = consulOpts.qryOpts
+	c.opts = consulOpts
 
 	return nil
 }
 
+func (c *consulBase) queryOptions() *consul.QueryOptions {
+	if c.qryOpts == nil {
+		return &consul.QueryOptions{}
+	}
+
+	qry := *c.qryOpts
+	return &qry
+}
+
+// Source implements SourceNamer.
+func (c *consulProvider) Source() string {
+	return "consul:" + c.cfgPath
+}
+
 // ProvideConfig implements ConfigProvider
 func (c *consulProvider) ProvideConfig() (io.Reader, error) {
+	return c.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is passed to the query.
+func (c *consulProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
 	if err := c.lazyInit(); err != nil {
 		return nil, fmt.Errorf("init lazy: %w", err)
 	}
 
-	qry := c.qryOpts
-	if qry == nil {
-		qry = &consul.QueryOptions{}
-	}
-
-	kv, _, err := c.client.KV().Get(c.cfgPath, qry)
+	kv, _, err := c.client.KV().Get(c.cfgPath, c.queryOptions().WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	if kv == nil {
-		return nil, fmt.Errorf("consul get kv: key '%s' doesn't exist", c.cfgPath)
+		return nil, fmt.Errorf("consul get kv: key '%s' doesn't exist: %w", c.cfgPath, ErrSourceNotFound)
 	}
 
 	return bytes.NewBuffer(kv.Value), nil
 }
 
+// Watch implements WatchableProvider.
+//
+// It uses Consul blocking queries to get notified as soon as the key is modified.
+// Deletion of the key isn't notified, the last provided config stays actual until the key
+// appears again. Failed queries are retried with exponential backoff.
+func (c *consulProvider) Watch(ctx context.Context) (<-chan struct{}, error) {
+	if err := c.lazyInit(); err != nil {
+		return nil, fmt.Errorf("init lazy: %w", err)
+	}
+
+	return watchConsul(ctx, c.opts, func(qry *consul.QueryOptions) ([]byte, *consul.QueryMeta, error) {
+		merged := c.queryOptions()
+		merged.WaitIndex = qry.WaitIndex
+		merged.WaitTime = qry.WaitTime
+
+		kv, meta, err := c.client.KV().Get(c.cfgPath, merged.WithContext(qry.Context()))
+		if err != nil || kv == nil {
+			return nil, meta, err
+		}
+
+		return kv.Value, meta, nil
+	}), nil
+}
+
+// consulQuery performs blocking query. It returns nil value if nothing is found.
+type consulQuery func(qry *consul.QueryOptions) ([]byte, *consul.QueryMeta, error)
+
+type consulWatch struct {
+	opts   consulOpts
+	query  consulQuery
+	notify chan struct{}
+	index  uint64
+	last   []byte
+	seen   bool
+}
+
+// watchConsul runs blocking queries in background until ctx is done and notifies when queried
+// value changes. The initial value is queried synchronously, so changes made after the call
+// aren't missed. See https://developer.hashicorp.com/consul/api-docs/features/blocking for details.
+func watchConsul(ctx context.Context, opts consulOpts, query consulQuery) <-chan struct{} {
+	w := &consulWatch{
+		opts:   opts,
+		query:  query,
+		notify: make(chan struct{}, 1),
+	}
+
+	if value, meta, err := query((&consul.QueryOptions{}).WithContext(ctx)); err == nil {
+		w.update(value, meta)
+	}
+
+	go w.run(ctx)
+	return w.notify
+}
+
+func (w *consulWatch) run(ctx context.Context) {
+	defer close(w.notify)
+
+	backoff := w.opts.minBackoff
+	for ctx.Err() == nil {
+		qry := &consul.QueryOptions{
+			WaitIndex: w.index,
+			WaitTime:  w.opts.waitTime,
+		}
+
+		value, meta, err := w.query(qry.WithContext(ctx))
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff = min(2*backoff, w.opts.maxBackoff)
+			continue
+		}
+		backoff = w.opts.minBackoff
+
+		if w.update(value, meta) {
+			trySend(w.notify)
+		}
+	}
+}
+
+// update remembers query result and reports whether the value changed since the last seen one.
+func (w *consulWatch) update(value []byte, meta *consul.QueryMeta) bool {
+	switch {
+	case meta.LastIndex < w.index:
+		// Index went backwards, e.g. after snapshot restore, so start over.
+		w.index = 0
+	case meta.LastIndex == 0:
+		w.index = 1
+	default:
+		w.index = meta.LastIndex
+	}
+
+	if value == nil {
+		return false
+	}
+
+	changed := w.seen && !bytes.Equal(value, w.last)
+	w.seen, w.last = true, value
+
+	return changed
+}
+
 // Overrides consul client.
 func ConsulWithClient(client *consul.Client) ConsulOption {
 	return func(v *consulOpts) error {
@@ -103,15 +255,45 @@ func ConsulWithQueryOptions(qry *consul.QueryOptions) ConsulOption {
 	}
 }
 
+// Defines the maximum duration of a blocking query used by Watch.
+// By default, DefaultConsulWaitTime is used.
+func ConsulWithWaitTime(d time.Duration) ConsulOption {
+	return func(v *consulOpts) error {
+		if d <= 0 {
+			return fmt.Errorf("wait time must be positive, got %s", d)
+		}
+
+		v.waitTime = d
+		return nil
+	}
+}
+
+// Defines the bounds of exponential backoff between failed queries used by Watch.
+// By default, backoff starts from 1 second and grows up to 1 minute.
+func ConsulWithBackoff(minBackoff, maxBackoff time.Duration) ConsulOption {
+	return func(v *consulOpts) error {
+		if minBackoff <= 0 || maxBackoff < minBackoff {
+			return fmt.Errorf("invalid backoff bounds [%s, %s]", minBackoff, maxBackoff)
+		}
+
+		v.minBackoff = minBackoff
+		v.maxBackoff = maxBackoff
+		return nil
+	}
+}
+
 // Returns config provider that provides config from consul kv.
 //
 // If client wasn't passed with options, it's created with default config.
 //
 // By default, config will use CONSUL_HTTP_ADDR env as HTTP address.
 // If it's empty, localhost will be chosen.
+//
+// The provider implements WatchableProvider, so it can be used with Watch to reload config
+// when the key is modified.
 func FromConsul(cfgPath string, opts ...ConsulOption) *consulProvider {
 	return &consulProvider{
-		cfgPath:  cfgPath,
-		funcOpts: opts,
+		consulBase: consulBase{funcOpts: opts},
+		cfgPath:    cfgPath,
 	}
 }
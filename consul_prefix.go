@@ -0,0 +1,223 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+var (
+	_ ConfigProvider        = &consulPrefixProvider{}
+	_ ContextConfigProvider = &consulPrefixProvider{}
+	_ WatchableProvider     = &consulPrefixProvider{}
+	_ SourceNamer           = &consulPrefixProvider{}
+)
+
+type consulPrefixProvider struct {
+	consulBase
+	prefix string
+}
+
+// Source implements SourceNamer.
+func (c *consulPrefixProvider) Source() string {
+	return "consul-prefix:" + c.prefix
+}
+
+// ProvideConfig implements ConfigProvider.
+func (c *consulPrefixProvider) ProvideConfig() (io.Reader, error) {
+	return c.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is passed to the query.
+func (c *consulPrefixProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	if err := c.lazyInit(); err != nil {
+		return nil, fmt.Errorf("init lazy: %w", err)
+	}
+
+	data, _, err := c.list(c.queryOptions().WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, fmt.Errorf("consul list kv: prefix '%s' doesn't contain keys: %w", c.prefix, ErrSourceNotFound)
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// Watch implements WatchableProvider.
+//
+// It uses Consul blocking queries to get notified as soon as any key under the prefix is modified.
+// Failed queries are retried with exponential backoff.
+func (c *consulPrefixProvider) Watch(ctx context.Context) (<-chan struct{}, error) {
+	if err := c.lazyInit(); err != nil {
+		return nil, fmt.Errorf("init lazy: %w", err)
+	}
+
+	return watchConsul(ctx, c.opts, func(qry *consul.QueryOptions) ([]byte, *consul.QueryMeta, error) {
+		merged := c.queryOptions()
+		merged.WaitIndex = qry.WaitIndex
+		merged.WaitTime = qry.WaitTime
+
+		return c.list(merged.WithContext(qry.Context()))
+	}), nil
+}
+
+// list lists keys under the prefix and assembles them into JSON document.
+// It returns nil document if there are no keys.
+func (c *consulPrefixProvider) list(qry *consul.QueryOptions) ([]byte, *consul.QueryMeta, error) {
+	prefix := strings.TrimPrefix(c.prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	pairs, meta, err := c.client.KV().List(prefix, qry)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	tree := map[string]any{}
+	found := false
+	for _, pair := range pairs {
+		key := strings.TrimPrefix(pair.Key, prefix)
+		if key == "" || strings.HasSuffix(key, "/") {
+			// Folder.
+			continue
+		}
+
+		if err := c.insert(tree, key, pair.Value); err != nil {
+			return nil, meta, fmt.Errorf("assemble key '%s': %w", pair.Key, err)
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, meta, nil
+	}
+
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil, meta, fmt.Errorf("encode config: %w", err)
+	}
+
+	return data, meta, nil
+}
+
+func (c *consulPrefixProvider) insert(tree map[string]any, key string, value []byte) error {
+	return insertTreeKey(tree, key, value, c.opts.typedValues, c.opts.keyTransform)
+}
+
+// insertTreeKey inserts value into tree by key split by "/" into nested objects.
+// Segments are transformed by keyTransform if it isn't nil. Values are converted by typedValue
+// if typedValues is true, otherwise they are strings.
+func insertTreeKey(tree map[string]any, key string, value []byte, typedValues bool, keyTransform func(string) string) error {
+	var segments []string
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" {
+			continue
+		}
+
+		if keyTransform != nil {
+			segment = keyTransform(segment)
+		}
+		segments = append(segments, segment)
+	}
+
+	node := tree
+	for _, segment := range segments[:len(segments)-1] {
+		switch child := node[segment].(type) {
+		case nil:
+			next := map[string]any{}
+			node[segment] = next
+			node = next
+		case map[string]any:
+			node = child
+		default:
+			return fmt.Errorf("segment '%s' has both value and nested keys", segment)
+		}
+	}
+
+	leaf := segments[len(segments)-1]
+	if _, ok := node[leaf]; ok {
+		return fmt.Errorf("segment '%s' has both value and nested keys", leaf)
+	}
+
+	if typedValues {
+		node[leaf] = typedValue(value)
+	} else {
+		node[leaf] = string(value)
+	}
+
+	return nil
+}
+
+// typedValue converts raw value into bool, number or JSON value if it's possible.
+// Otherwise, it returns value as string.
+func typedValue(value []byte) any {
+	s := strings.TrimSpace(string(value))
+	if s == "" || !json.Valid([]byte(s)) {
+		return string(value)
+	}
+
+	switch s[0] {
+	case 't', 'f':
+		return s == "true"
+	case '{', '[':
+		return json.RawMessage(s)
+	case '"', 'n':
+		return string(value)
+	default:
+		return json.Number(s)
+	}
+}
+
+// Makes FromConsulPrefix convert values into booleans ("true", "false"), numbers and JSON
+// objects or arrays when possible. By default, all values are strings.
+func ConsulWithValueTyping() ConsulOption {
+	return func(v *consulOpts) error {
+		v.typedValues = true
+		return nil
+	}
+}
+
+// Defines the function that FromConsulPrefix applies to every key segment before
+// using it as object key, e.g. strings.ToLower.
+func ConsulWithKeyTransform(transform func(segment string) string) ConsulOption {
+	return func(v *consulOpts) error {
+		if transform == nil {
+			return errors.New("got nil key transform")
+		}
+
+		v.keyTransform = transform
+		return nil
+	}
+}
+
+// Returns config provider that assembles config from all consul kv keys under prefix.
+// Keys are split by "/" into nested JSON objects, so it can be decoded with the default decoder.
+//
+// Example:
+//
+//	// Consul KV
+//	service/db/host = localhost
+//	service/db/port = 5432
+//	// FromConsulPrefix("service", ConsulWithValueTyping()) provides
+//	{"db": {"host": "localhost", "port": 5432}}
+//
+// If client wasn't passed with options, it's created with default config.
+//
+// The provider implements WatchableProvider, so it can be used with Watch to reload config
+// when any key under the prefix is modified.
+func FromConsulPrefix(prefix string, opts ...ConsulOption) *consulPrefixProvider {
+	return &consulPrefixProvider{
+		consulBase: consulBase{funcOpts: opts},
+		prefix:     prefix,
+	}
+}
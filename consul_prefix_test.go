@@ -0,0 +1,116 @@
+package config_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testConsulPrefixConfig struct {
+	Name string `json:"name"`
+	DB   struct {
+		Host    string         `json:"host"`
+		Port    int            `json:"port"`
+		TLS     bool           `json:"tls"`
+		Options map[string]any `json:"options"`
+	} `json:"db"`
+}
+
+func TestConsulPrefixProvider(t *testing.T) {
+	fake, client := newFakeConsul(t)
+	fake.put("service/", []byte{}, 0)
+	fake.put("service/name", []byte("foo"), 0)
+	fake.put("service/db/host", []byte("localhost"), 0)
+	fake.put("service/db/port", []byte("5432"), 0)
+	fake.put("service/db/tls", []byte("true"), 0)
+	fake.put("service/db/options", []byte(`{"timeout": "1s"}`), 0)
+	fake.put("service-other/name", []byte("bar"), 0)
+
+	t.Run("Strings", func(t *testing.T) {
+		r := require.New(t)
+
+		dataReader, err := config.FromConsulPrefix("service", config.ConsulWithClient(client)).ProvideConfig()
+		r.NoError(err)
+
+		data, err := io.ReadAll(dataReader)
+		r.NoError(err)
+		r.JSONEq(`{
+			"name": "foo",
+			"db": {"host": "localhost", "port": "5432", "tls": "true", "options": "{\"timeout\": \"1s\"}"}
+		}`, string(data))
+	})
+
+	t.Run("TypedValues", func(t *testing.T) {
+		r := require.New(t)
+
+		cfg, err := config.New[testConsulPrefixConfig](config.FromConsulPrefix("/service/",
+			config.ConsulWithClient(client),
+			config.ConsulWithValueTyping(),
+		))
+		r.NoError(err)
+		r.Equal("foo", cfg.Name)
+		r.Equal("localhost", cfg.DB.Host)
+		r.Equal(5432, cfg.DB.Port)
+		r.True(cfg.DB.TLS)
+		r.Equal(map[string]any{"timeout": "1s"}, cfg.DB.Options)
+	})
+
+	t.Run("KeyTransform", func(t *testing.T) {
+		r := require.New(t)
+		fake.put("upper/DB/HOST", []byte("localhost"), 0)
+
+		cfg, err := config.New[testConsulPrefixConfig](config.FromConsulPrefix("upper",
+			config.ConsulWithClient(client),
+			config.ConsulWithKeyTransform(strings.ToLower),
+		))
+		r.NoError(err)
+		r.Equal("localhost", cfg.DB.Host)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		fake.put("conflict/db", []byte("value"), 0)
+		fake.put("conflict/db/host", []byte("localhost"), 0)
+
+		_, err := config.FromConsulPrefix("conflict", config.ConsulWithClient(client)).ProvideConfig()
+		require.Error(t, err)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := config.FromConsulPrefix("missing", config.ConsulWithClient(client)).ProvideConfig()
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		_, err := config.FromConsulPrefix("service", config.ConsulWithKeyTransform(nil)).ProvideConfig()
+		require.Error(t, err)
+	})
+}
+
+func TestConsulPrefixProvider_Watch(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake, client := newFakeConsul(t)
+	fake.put("service/db/host", []byte("localhost"), 0)
+
+	w, err := config.Watch[testConsulPrefixConfig](ctx, config.FromConsulPrefix("service",
+		config.ConsulWithClient(client),
+		config.ConsulWithValueTyping(),
+	))
+	r.NoError(err)
+	r.Equal("localhost", w.Config().DB.Host)
+
+	fake.put("service/db/port", []byte("5432"), 0)
+	select {
+	case change := <-w.Changes():
+		r.Equal(5432, change.New.DB.Port)
+	case <-time.After(time.Second):
+		r.FailNow("no change")
+	}
+}
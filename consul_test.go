@@ -2,8 +2,16 @@ package config_test
 
 import (
 	"context"
+	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/MordaTeam/go-config"
 	"github.com/docker/go-connections/nat"
@@ -70,3 +78,189 @@ func TestConsulProvider(t *testing.T) {
 		r.Equal([]byte(`{"foo": "bar"}`), data)
 	})
 }
+
+// fakeConsul is a stand-in of Consul KV HTTP API that supports blocking queries.
+type fakeConsul struct {
+	mu      sync.Mutex
+	index   uint64
+	kv      map[string][]byte
+	fails   int
+	changed chan struct{}
+}
+
+func newFakeConsul(t testing.TB) (*fakeConsul, *api.Client) {
+	f := &fakeConsul{
+		index:   1,
+		kv:      map[string][]byte{},
+		changed: make(chan struct{}),
+	}
+
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	require.NoError(t, err)
+
+	return f, client
+}
+
+// put sets key and bumps index. Zero index keeps the current one.
+func (f *fakeConsul) put(key string, value []byte, index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if value == nil {
+		delete(f.kv, key)
+	} else {
+		f.kv[key] = value
+	}
+
+	if index == 0 {
+		index = f.index + 1
+	}
+	f.index = index
+
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) failNext(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fails = n
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := strings.CutPrefix(r.URL.Path, "/v1/kv/")
+	if !ok || r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	if f.fails > 0 {
+		f.fails--
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	waitIndex, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	if waitIndex != 0 && waitIndex == f.index {
+		changed := f.changed
+		f.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-time.After(100 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+
+		f.mu.Lock()
+	}
+	defer f.mu.Unlock()
+
+	var pairs api.KVPairs
+	for k, v := range f.kv {
+		if k == key || (r.URL.Query().Has("recurse") && strings.HasPrefix(k, key)) {
+			pairs = append(pairs, &api.KVPair{Key: k, Value: v, ModifyIndex: f.index})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	w.Header().Set("X-Consul-LastContact", "0")
+	w.Header().Set("X-Consul-KnownLeader", "true")
+
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+func TestConsulProvider_Watch(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake, client := newFakeConsul(t)
+	fake.put("foo/bar", []byte(`{"foo": "bar"}`), 0)
+
+	provider := config.FromConsul("foo/bar",
+		config.ConsulWithClient(client),
+		config.ConsulWithWaitTime(time.Second),
+		config.ConsulWithBackoff(time.Millisecond, 10*time.Millisecond),
+	)
+
+	w, err := config.Watch[testConfig](ctx, provider)
+	r.NoError(err)
+	r.Equal(testConfig{Foo: "bar"}, w.Config())
+
+	requireChange := func(expected testConfig) {
+		t.Helper()
+
+		select {
+		case change := <-w.Changes():
+			r.Equal(expected, change.New)
+		case <-time.After(time.Second):
+			r.FailNow("no change")
+		}
+	}
+
+	fake.put("foo/bar", []byte(`{"foo": "buz"}`), 0)
+	requireChange(testConfig{Foo: "buz"})
+
+	// Unrelated modification doesn't lead to reload.
+	fake.put("foo/other", []byte(`{}`), 0)
+	select {
+	case change := <-w.Changes():
+		r.FailNow("unexpected change", change)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Failed queries are retried.
+	fake.failNext(3)
+	time.Sleep(300 * time.Millisecond)
+	fake.put("foo/bar", []byte(`{"foo": "after errors"}`), 0)
+	requireChange(testConfig{Foo: "after errors"})
+
+	// Index reset.
+	fake.put("foo/bar", []byte(`{"foo": "after reset"}`), 1)
+	requireChange(testConfig{Foo: "after reset"})
+
+	// Deleted key is not notified.
+	fake.put("foo/bar", nil, 0)
+	fake.put("foo/bar", []byte(`{"foo": "recreated"}`), 0)
+	requireChange(testConfig{Foo: "recreated"})
+}
+
+func TestConsulProvider_WatchInvalidOptions(t *testing.T) {
+	_, client := newFakeConsul(t)
+
+	_, err := config.FromConsul("foo", config.ConsulWithClient(client), config.ConsulWithWaitTime(0)).
+		Watch(context.Background())
+	require.Error(t, err)
+
+	_, err = config.FromConsul("foo", config.ConsulWithClient(client), config.ConsulWithBackoff(time.Second, time.Millisecond)).
+		Watch(context.Background())
+	require.Error(t, err)
+}
+
+func TestConsulProvider_NotFound(t *testing.T) {
+	fake, client := newFakeConsul(t)
+	fake.put("app/base", []byte(`{"foo": "base"}`), 0)
+
+	_, err := config.New[testConfig](config.FromConsul("app/local", config.ConsulWithClient(client)))
+	require.ErrorIs(t, err, config.ErrSourceNotFound)
+
+	cfg, err := config.Multi[testConfig]().
+		Add(config.FromConsul("app/base", config.ConsulWithClient(client))).
+		AddOptional(config.FromConsul("app/local", config.ConsulWithClient(client))).
+		AllOf()
+	require.NoError(t, err)
+	require.Equal(t, testConfig{Foo: "base"}, cfg)
+}
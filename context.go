@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"io"
+)
+
+// ContextConfigProvider is implemented by providers that support cancellation and deadlines
+// of ctx, e.g. Consul providers pass ctx to queries. It's used by NewContext, FillContext
+// and context variants of Multi methods.
+type ContextConfigProvider interface {
+	ConfigProvider
+	ProvideConfigContext(ctx context.Context) (io.Reader, error)
+}
+
+// provideConfig provides data by provider with ctx.
+//
+// Providers that don't implement ContextConfigProvider are called in a separate goroutine,
+// so the call returns the error of ctx when it's done, even if the provider hangs. Reader
+// provided after that is closed if it implements the io.Closer interface.
+// The provided reader returns the error of ctx when it's done.
+func provideConfig(ctx context.Context, provider ConfigProvider) (io.Reader, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if ctxProvider, ok := provider.(ContextConfigProvider); ok {
+		r, err := ctxProvider.ProvideConfigContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return withContext(ctx, r), nil
+	}
+
+	if ctx.Done() == nil {
+		r, err := provider.ProvideConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		return r, nil
+	}
+
+	type result struct {
+		r   io.Reader
+		err error
+	}
+
+	// The result is handed over synchronously, so the reader is owned either by the caller
+	// or by the goroutine, which closes it if the caller has gone.
+	res := make(chan result)
+	go func() {
+		r, err := provider.ProvideConfig()
+		select {
+		case res <- result{r: r, err: err}:
+		case <-ctx.Done():
+			if c, ok := r.(io.Closer); ok {
+				_ = c.Close()
+			}
+		}
+	}()
+
+	select {
+	case res := <-res:
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		return withContext(ctx, res.r), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ctxReader is a reader that fails with the error of ctx when it's done.
+// It closes the underlying reader if it implements the io.Closer interface.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func withContext(ctx context.Context, r io.Reader) io.Reader {
+	if ctx.Done() == nil {
+		return r
+	}
+
+	return &ctxReader{ctx: ctx, r: r}
+}
+
+// Read implements io.Reader.
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
+
+func (r *ctxReader) commitDecoded() {
+	if c, ok := r.r.(decodedCommitter); ok {
+		c.commitDecoded()
+	}
+}
+
+// FormatHint implements FormatHinter. It returns the hint of the underlying reader.
+func (r *ctxReader) FormatHint() string {
+	return formatHint(r.r)
+}
+
+// Close implements io.Closer.
+func (r *ctxReader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
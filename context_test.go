@@ -0,0 +1,130 @@
+package config_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+)
+
+// hungProvider is a provider without context support that blocks until release is closed.
+// If closed isn't nil, it's closed when the provided reader is closed.
+type hungProvider struct {
+	release chan struct{}
+	closed  chan struct{}
+}
+
+func (p *hungProvider) ProvideConfig() (io.Reader, error) {
+	<-p.release
+	return &notifyCloser{Reader: strings.NewReader(`{"foo": "bar"}`), closed: p.closed}, nil
+}
+
+type notifyCloser struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (r *notifyCloser) Close() error {
+	if r.closed != nil {
+		close(r.closed)
+	}
+	return nil
+}
+
+func TestNewContext(t *testing.T) {
+	t.Run("provider without context", func(t *testing.T) {
+		provider := &hungProvider{release: make(chan struct{})}
+		defer close(provider.release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := config.NewContext[testConfig](ctx, provider)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		var provErr *config.ProviderError
+		require.ErrorAs(t, err, &provErr)
+	})
+
+	t.Run("late reader is closed", func(t *testing.T) {
+		provider := &hungProvider{release: make(chan struct{}), closed: make(chan struct{})}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := config.NewContext[testConfig](ctx, provider)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(provider.release)
+		select {
+		case <-provider.closed:
+		case <-time.After(time.Second):
+			t.Fatal("reader provided after cancellation isn't closed")
+		}
+	})
+
+	t.Run("consul", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		t.Cleanup(srv.Close)
+
+		client, err := api.NewClient(&api.Config{Address: srv.URL})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = config.NewContext[testConfig](ctx, config.FromConsul("foo/bar", config.ConsulWithClient(client)))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		path := writeFile(t, "config.json", `{"foo": "bar"}`)
+
+		cfg, err := config.NewContext[testConfig](context.Background(), config.FromFile(path))
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "bar"}, cfg)
+	})
+}
+
+func TestFillContext(t *testing.T) {
+	path := writeFile(t, "config.json", `{"foo": "bar"}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cfg := testConfig{Foo: "old"}
+	err := config.FillContext(ctx, &cfg, config.FromFile(path))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, testConfig{Foo: "old"}, cfg)
+
+	err = config.FillContext(context.Background(), &cfg, config.FromFile(path))
+	require.NoError(t, err)
+	require.Equal(t, testConfig{Foo: "bar"}, cfg)
+}
+
+func TestMulti_AllOfContext(t *testing.T) {
+	provider := &hungProvider{release: make(chan struct{})}
+	defer close(provider.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	multi := config.Multi[testConfig]().
+		Add(config.FromReader(strings.NewReader(`{"foo": "bar"}`))).
+		Add(config.FallbackProvider(provider, config.FromReader(strings.NewReader(`{"foo": "buz"}`))))
+
+	_, err := multi.AllOfContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = multi.OneOfContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
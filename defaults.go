@@ -0,0 +1,298 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultTag = "default"
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// setDefaults sets zero fields of struct pointed by v to values from `default` tag.
+// Nested structs are handled recursively, nil pointers to structs are left as is.
+//
+// Supported types are strings, booleans, numbers, time.Duration, encoding.TextUnmarshaler,
+// pointers, slices and arrays ("a,b,c" or JSON array), maps ("k1:v1,k2:v2" or JSON object)
+// and structs (JSON object). Defaults of cmdline options (fields with `long` or `short` tag)
+// follow go-flags library: repeated `default` tags are items of slices and maps.
+func setDefaults(v any) error {
+	return setStructDefaults(reflect.ValueOf(v), "")
+}
+
+func setStructDefaults(v reflect.Value, path string) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		fieldPath := joinPath(path, field.Name)
+		fieldVal := v.Field(i)
+
+		def, ok := field.Tag.Lookup(defaultTag)
+		if !ok {
+			if err := setStructDefaults(fieldVal, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !fieldVal.CanSet() || !fieldVal.IsZero() {
+			continue
+		}
+
+		var err error
+		if isFlagField(field) {
+			err = setFlagDefaults(fieldVal, tagValues(field.Tag, defaultTag))
+		} else {
+			err = setFromString(fieldVal, def)
+		}
+		if err != nil {
+			return fmt.Errorf("set default of field '%s': %w", fieldPath, err)
+		}
+	}
+
+	return nil
+}
+
+// isFlagField reports whether field is an option of cmdline decoder.
+func isFlagField(field reflect.StructField) bool {
+	return field.Tag.Get("long") != "" || field.Tag.Get("short") != ""
+}
+
+// setFlagDefaults sets v to defaults like go-flags library does for options: each `default`
+// tag is an item of slices or a "key:value" item of maps, items aren't split by commas.
+// Other types are set from the last default.
+func setFlagDefaults(v reflect.Value, defs []string) error {
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return setFromString(v, defs[len(defs)-1])
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), len(defs), len(defs)))
+		for i, def := range defs {
+			if err := setFromString(v.Index(i), def); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, def := range defs {
+			key, val, ok := strings.Cut(def, ":")
+			if !ok {
+				return fmt.Errorf("invalid map item '%s', expected 'key:value'", def)
+			}
+
+			keyVal := reflect.New(v.Type().Key()).Elem()
+			if err := setFromString(keyVal, key); err != nil {
+				return fmt.Errorf("key '%s': %w", key, err)
+			}
+
+			valVal := reflect.New(v.Type().Elem()).Elem()
+			if err := setFromString(valVal, val); err != nil {
+				return fmt.Errorf("value of key '%s': %w", key, err)
+			}
+
+			m.SetMapIndex(keyVal, valVal)
+		}
+		v.Set(m)
+	default:
+		return setFromString(v, defs[len(defs)-1])
+	}
+
+	return nil
+}
+
+// tagValues returns values of all occurrences of key in tag. Unlike reflect.StructTag.Lookup,
+// it doesn't stop on the first one, as go-flags library allows repeated tags.
+func tagValues(tag reflect.StructTag, key string) []string {
+	var values []string
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon. A space, a quote or a control character is a syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// Scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if name == key {
+			value, err := strconv.Unquote(qvalue)
+			if err != nil {
+				break
+			}
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// setFromString parses s into v according to the type of v.
+func setFromString(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setFromString(elem.Elem(), s); err != nil {
+			return err
+		}
+
+		v.Set(elem)
+		return nil
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice, reflect.Array:
+		if strings.HasPrefix(strings.TrimSpace(s), "[") {
+			return json.Unmarshal([]byte(s), v.Addr().Interface())
+		}
+
+		var items []string
+		if s != "" {
+			items = strings.Split(s, ",")
+		}
+
+		if v.Kind() == reflect.Array {
+			if len(items) > v.Len() {
+				return fmt.Errorf("too many items for array of length %d", v.Len())
+			}
+		} else {
+			v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		}
+
+		for i, item := range items {
+			if err := setFromString(v.Index(i), strings.TrimSpace(item)); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		if strings.HasPrefix(strings.TrimSpace(s), "{") {
+			return json.Unmarshal([]byte(s), v.Addr().Interface())
+		}
+
+		m := reflect.MakeMap(v.Type())
+		for _, item := range strings.Split(s, ",") {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+
+			key, val, ok := strings.Cut(item, ":")
+			if !ok {
+				return fmt.Errorf("invalid map item '%s', expected 'key:value'", item)
+			}
+
+			keyVal := reflect.New(v.Type().Key()).Elem()
+			if err := setFromString(keyVal, strings.TrimSpace(key)); err != nil {
+				return fmt.Errorf("key '%s': %w", key, err)
+			}
+
+			valVal := reflect.New(v.Type().Elem()).Elem()
+			if err := setFromString(valVal, strings.TrimSpace(val)); err != nil {
+				return fmt.Errorf("value of key '%s': %w", key, err)
+			}
+
+			m.SetMapIndex(keyVal, valVal)
+		}
+		v.Set(m)
+	default:
+		return json.Unmarshal([]byte(s), v.Addr().Interface())
+	}
+
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
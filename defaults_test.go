@@ -0,0 +1,177 @@
+package config_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testDefaultsConfig struct {
+	Name    string            `json:"name" ini:"name" env:"NAME" long:"name" default:"service"`
+	Port    int               `json:"port" ini:"port" env:"PORT" long:"port" default:"8080"`
+	Debug   bool              `json:"debug" ini:"debug" env:"DEBUG" default:"true"`
+	Timeout time.Duration     `json:"timeout" ini:"timeout" default:"5s"`
+	Ratio   *float64          `json:"ratio" ini:"ratio" env:"RATIO" default:"0.5"`
+	Hosts   []string          `json:"hosts" ini:"hosts" default:"localhost, 127.0.0.1"`
+	Ports   []int             `json:"ports" ini:"-" default:"[80, 443]"`
+	Labels  map[string]string `json:"labels" ini:"-" default:"team:core,env:dev"`
+	Started time.Time         `json:"started" ini:"-" default:"2025-01-01T00:00:00Z"`
+	DB      testDefaultsDB    `json:"db" ini:"db" group:"db" namespace:"db"`
+}
+
+type testDefaultsDB struct {
+	Host string `json:"host" ini:"host" long:"host" default:"localhost"`
+	Pool int    `json:"pool" ini:"pool" long:"pool" default:"10"`
+}
+
+func expDefaultsConfig() testDefaultsConfig {
+	ratio := 0.5
+	return testDefaultsConfig{
+		Name:    "service",
+		Port:    8080,
+		Debug:   true,
+		Timeout: 5 * time.Second,
+		Ratio:   &ratio,
+		Hosts:   []string{"localhost", "127.0.0.1"},
+		Ports:   []int{80, 443},
+		Labels:  map[string]string{"team": "core", "env": "dev"},
+		Started: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		DB: testDefaultsDB{
+			Host: "localhost",
+			Pool: 10,
+		},
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		cfg, err := config.New[testDefaultsConfig](
+			config.FromReader(strings.NewReader(`{"name": "foo", "db": {"pool": 20}}`)),
+		)
+		require.NoError(t, err)
+
+		exp := expDefaultsConfig()
+		exp.Name = "foo"
+		exp.DB.Pool = 20
+		require.Equal(t, exp, cfg)
+	})
+
+	t.Run("INI", func(t *testing.T) {
+		cfg, err := config.New[testDefaultsConfig](
+			config.FromReader(strings.NewReader("port = 9090\n[db]\nhost = db.local\n")),
+			config.WithDecoder(config.IniDecoder),
+		)
+		require.NoError(t, err)
+
+		exp := expDefaultsConfig()
+		exp.Port = 9090
+		exp.DB.Host = "db.local"
+		require.Equal(t, exp, cfg)
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("PORT", "9090")
+
+		cfg, err := config.New[testDefaultsConfig](config.FromEnv(), config.WithDecoder(config.EnvDecoder))
+		require.NoError(t, err)
+
+		exp := expDefaultsConfig()
+		exp.Port = 9090
+		require.Equal(t, exp, cfg)
+
+		t.Setenv("RATIO", "0.7")
+
+		cfg, err = config.New[testDefaultsConfig](config.FromEnv(), config.WithDecoder(config.EnvDecoder))
+		require.NoError(t, err)
+		require.Equal(t, 0.7, *cfg.Ratio)
+	})
+
+	t.Run("Cmdline", func(t *testing.T) {
+		os.Args = []string{"cli", "--db.host", "db.local"}
+
+		cfg, err := config.New[testDefaultsConfig](config.FromCmdline(), config.WithDecoder(config.CmdlineDecoder))
+		require.NoError(t, err)
+
+		exp := expDefaultsConfig()
+		exp.DB.Host = "db.local"
+		require.Equal(t, exp, cfg)
+	})
+
+	t.Run("Multi", func(t *testing.T) {
+		os.Args = []string{"cli", "--port", "9090"}
+
+		// Cmdline doesn't reset values from the file to defaults.
+		cfg, err := config.Multi[testDefaultsConfig]().
+			Add(config.FromReader(strings.NewReader(`{"name": "foo", "db": {"host": "db.local"}}`))).
+			Add(config.FromCmdline(), config.WithDecoder(config.CmdlineDecoder)).
+			AllOf()
+		require.NoError(t, err)
+
+		exp := expDefaultsConfig()
+		exp.Name = "foo"
+		exp.Port = 9090
+		exp.DB.Host = "db.local"
+		require.Equal(t, exp, cfg)
+	})
+
+	t.Run("Fill", func(t *testing.T) {
+		cfg := testDefaultsConfig{Name: "filled"}
+		err := config.Fill(&cfg, config.FromReader(strings.NewReader(`{"port": 9090}`)))
+		require.NoError(t, err)
+
+		exp := expDefaultsConfig()
+		exp.Name = "filled"
+		exp.Port = 9090
+		require.Equal(t, exp, cfg)
+	})
+}
+
+func TestDefaults_Flags(t *testing.T) {
+	// Defaults of cmdline options follow go-flags: repeated tags are items, commas aren't split.
+	type flagsConfig struct {
+		Tags   []string          `json:"tags" long:"tag" default:"a" default:"b"`
+		Csv    []string          `json:"csv" long:"csv" default:"x,y"`
+		Labels map[string]string `json:"labels" long:"label" default:"team:core" default:"env:dev"`
+		Port   int               `json:"port" long:"port" default:"8080"`
+	}
+
+	exp := flagsConfig{
+		Tags:   []string{"a", "b"},
+		Csv:    []string{"x,y"},
+		Labels: map[string]string{"team": "core", "env": "dev"},
+		Port:   8080,
+	}
+
+	os.Args = []string{"cli"}
+	cfg, err := config.New[flagsConfig](config.FromCmdline(), config.WithDecoder(config.CmdlineDecoder))
+	require.NoError(t, err)
+	require.Equal(t, exp, cfg)
+
+	cfg, err = config.New[flagsConfig](config.FromReader(strings.NewReader(`{}`)))
+	require.NoError(t, err)
+	require.Equal(t, exp, cfg)
+
+	os.Args = []string{"cli", "--tag", "c"}
+	cfg, err = config.New[flagsConfig](config.FromCmdline(), config.WithDecoder(config.CmdlineDecoder))
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, cfg.Tags)
+	require.Equal(t, []string{"x,y"}, cfg.Csv)
+}
+
+func TestDefaults_Invalid(t *testing.T) {
+	type invalidConfig struct {
+		Nested struct {
+			Port int `json:"port" default:"http"`
+		} `json:"nested"`
+	}
+
+	_, err := config.New[invalidConfig](config.FromReader(strings.NewReader(`{}`)))
+	require.ErrorContains(t, err, "Nested.Port")
+
+	_, err = config.Multi[invalidConfig]().Add(config.FromReader(strings.NewReader(`{}`))).AllOf()
+	require.ErrorContains(t, err, "Nested.Port")
+}
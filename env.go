@@ -1,11 +1,15 @@
 package config
 
 import (
+	"bufio"
 	"bytes"
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/caarlos0/env/v9"
@@ -14,6 +18,7 @@ import (
 var (
 	_ Decoder        = &envDecoder{}
 	_ ConfigProvider = &envProvider{}
+	_ SourceNamer    = &envProvider{}
 )
 
 type EnvOptions struct {
@@ -31,6 +36,11 @@ type envProvider struct {
 	keyToLowerCase bool
 }
 
+// Source implements SourceNamer.
+func (*envProvider) Source() string {
+	return "env"
+}
+
 // ProvideConfig implements ConfigProvider.
 func (e *envProvider) ProvideConfig() (io.Reader, error) {
 	hostname, err := os.Hostname()
@@ -98,19 +108,105 @@ func FromEnv() *envProvider {
 
 type envDecoder struct {
 	mapEnv map[string]string
+	err    error
 }
 
 // Decode implements Decoder.
 func (e *envDecoder) Decode(v any) error {
-	if err := env.ParseWithOptions(v, env.Options{
+	if e.err != nil {
+		return e.err
+	}
+
+	opts := env.Options{
 		Environment: e.mapEnv,
-	}); err != nil {
+	}
+
+	// env library fails on non-nil pointers to non-struct values (e.g. set by defaults),
+	// so v is parsed through a copy without them.
+	dst := reflect.ValueOf(v)
+	if dst.Kind() != reflect.Pointer || dst.IsNil() || dst.Elem().Kind() != reflect.Struct {
+		if err := env.ParseWithOptions(v, opts); err != nil {
+			return fmt.Errorf("parse env: %w", err)
+		}
+		return nil
+	}
+
+	tmp := reflect.New(dst.Elem().Type())
+	copyWithoutScalarPointers(tmp.Elem(), dst.Elem())
+
+	if err := env.ParseWithOptions(tmp.Interface(), opts); err != nil {
 		return fmt.Errorf("parse env: %w", err)
 	}
 
+	copyParsedEnv(dst.Elem(), tmp.Elem())
 	return nil
 }
 
+// copyWithoutScalarPointers copies struct src to dst, setting pointers to non-struct values
+// to nil. Pointed structs are copied, so src isn't changed by parsing dst.
+func copyWithoutScalarPointers(dst, src reflect.Value) {
+	dst.Set(src)
+
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		switch {
+		case isEnvStruct(field.Type()):
+			copyWithoutScalarPointers(field, src.Field(i))
+		case field.Kind() == reflect.Pointer && !field.IsNil():
+			if !isEnvStruct(field.Type().Elem()) {
+				field.SetZero()
+				continue
+			}
+
+			elem := reflect.New(field.Type().Elem())
+			copyWithoutScalarPointers(elem.Elem(), src.Field(i).Elem())
+			field.Set(elem)
+		}
+	}
+}
+
+// copyParsedEnv copies fields of struct src parsed by env library to dst. Nil pointers of src
+// weren't set by the library, so pointers of dst are kept.
+func copyParsedEnv(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		srcField := src.Field(i)
+		switch {
+		case isEnvStruct(field.Type()):
+			copyParsedEnv(field, srcField)
+		case field.Kind() == reflect.Pointer:
+			if srcField.IsNil() {
+				continue
+			}
+
+			if !field.IsNil() && isEnvStruct(field.Type().Elem()) {
+				copyParsedEnv(field.Elem(), srcField.Elem())
+				continue
+			}
+
+			field.Set(srcField)
+		default:
+			field.Set(srcField)
+		}
+	}
+}
+
+// isEnvStruct reports whether t is a struct whose fields are parsed by env library one by one.
+// Other structs, e.g. time.Time and implementations of encoding.TextUnmarshaler, are parsed
+// as a whole value, so they are copied as a whole too.
+func isEnvStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && hasExportedFields(t) &&
+		!reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
 // Returns env decoder that parses envs to struct with tags.
 // Provider should return json config or nothing.
 // JSON config will be used as additional environment.
@@ -139,3 +235,93 @@ func EnvDecoder(r io.Reader) *envDecoder {
 	dec.mapEnv = mapEnv
 	return &dec
 }
+
+// Returns env decoder that parses env file (dotenv) to struct with tags.
+// Variables are taken only from the file, the environment of the process isn't used.
+//
+// Supported syntax:
+//
+//	# comment
+//	FOO=bar
+//	export BUZ="quoted value\nwith escapes" # inline comment
+//	QUX='literal value'
+//
+// If the file can't be read or parsed, Decode returns the error.
+func DotenvDecoder(r io.Reader) *envDecoder {
+	mapEnv, err := parseDotenv(r)
+	if err != nil {
+		return &envDecoder{err: err}
+	}
+
+	return &envDecoder{mapEnv: mapEnv}
+}
+
+func parseDotenv(r io.Reader) (map[string]string, error) {
+	mapEnv := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing '='", lineNum)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNum)
+		}
+
+		val = strings.TrimSpace(val)
+		switch {
+		case strings.HasPrefix(val, `"`):
+			end := closingQuote(val)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", lineNum)
+			}
+
+			unquoted, err := strconv.Unquote(val[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: unquote value: %w", lineNum, err)
+			}
+			val = unquoted
+		case strings.HasPrefix(val, "'"):
+			end := strings.Index(val[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", lineNum)
+			}
+			val = val[1 : end+1]
+		default:
+			if i := strings.Index(val, " #"); i >= 0 {
+				val = strings.TrimSpace(val[:i])
+			}
+		}
+
+		mapEnv[key] = val
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read env file: %w", err)
+	}
+
+	return mapEnv, nil
+}
+
+// closingQuote returns index of the double quote that closes the quoted string s or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/MordaTeam/go-config"
 	"github.com/stretchr/testify/assert"
@@ -95,3 +96,77 @@ func TestEnv_WithEnvProvider_WithLowerCase(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expectedConfig, cfg)
 }
+
+func TestDotenvDecoder(t *testing.T) {
+	type dotenvConfig struct {
+		Foo    string `env:"FOO"`
+		Bar    string `env:"BAR"`
+		Buz    string `env:"BUZ"`
+		Qux    string `env:"QUX"`
+		Port   int    `env:"PORT"`
+		Absent string `env:"ABSENT"`
+	}
+
+	t.Setenv("ABSENT", "from process env")
+
+	data := `
+# comment
+FOO=foo
+export BAR = "quoted # not comment\nnext line" # comment
+BUZ='single $quoted'
+QUX=plain value # comment
+PORT=8080
+`
+	cfg, err := config.New[dotenvConfig](
+		config.FromReader(strings.NewReader(data)),
+		config.WithDecoder(config.DotenvDecoder),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, dotenvConfig{
+		Foo:  "foo",
+		Bar:  "quoted # not comment\nnext line",
+		Buz:  "single $quoted",
+		Qux:  "plain value",
+		Port: 8080,
+	}, cfg)
+
+	_, err = config.New[dotenvConfig](
+		config.FromReader(strings.NewReader("FOO\n")),
+		config.WithDecoder(config.DotenvDecoder),
+	)
+	require.ErrorContains(t, err, "line 1")
+
+	_, err = config.New[dotenvConfig](
+		config.FromReader(strings.NewReader(`FOO="unterminated`)),
+		config.WithDecoder(config.DotenvDecoder),
+	)
+	require.Error(t, err)
+}
+
+func TestEnv_TextUnmarshaler(t *testing.T) {
+	type envTimes struct {
+		When time.Time `env:"WHEN"`
+	}
+	type timeConfig struct {
+		When   time.Time  `env:"WHEN"`
+		Since  *time.Time `env:"SINCE"`
+		Nested envTimes   `envPrefix:"NESTED_"`
+	}
+
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expected := timeConfig{When: when, Since: &when, Nested: envTimes{When: when}}
+
+	cfg, err := config.New[timeConfig](
+		config.FromReader(strings.NewReader(`{"WHEN": "2024-01-02T03:04:05Z", "SINCE": "2024-01-02T03:04:05Z", "NESTED_WHEN": "2024-01-02T03:04:05Z"}`)),
+		config.WithDecoder(config.EnvDecoder),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expected, cfg)
+
+	cfg, err = config.New[timeConfig](
+		config.FromReader(strings.NewReader("WHEN=2024-01-02T03:04:05Z\nSINCE=2024-01-02T03:04:05Z\nNESTED_WHEN=2024-01-02T03:04:05Z\n")),
+		config.WithDecoder(config.DotenvDecoder),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expected, cfg)
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ErrSourceNotFound is wrapped by errors of providers when the source of config doesn't exist,
+// e.g. a Consul key is missing. Missing files are reported by providers with os.ErrNotExist,
+// but ProviderError matches ErrSourceNotFound for them too.
+var ErrSourceNotFound = errors.New("source not found")
+
+// isSourceNotFound reports whether err means that the source of config doesn't exist.
+func isSourceNotFound(err error) bool {
+	return errors.Is(err, ErrSourceNotFound) || errors.Is(err, fs.ErrNotExist)
+}
+
+// ProviderError is returned when a provider fails to provide data, e.g. the source doesn't
+// exist or can't be read. Use errors.Is with ErrSourceNotFound or os.ErrNotExist to check
+// whether the source is missing.
+type ProviderError struct {
+	// Source is the name of the provider (see SourceNamer), empty if it's unknown.
+	Source string
+	Err    error
+}
+
+// Error implements error.
+func (e *ProviderError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("provide config: %v", e.Err)
+	}
+
+	return fmt.Sprintf("provide config from '%s': %v", e.Source, e.Err)
+}
+
+// Unwrap returns the error of the provider.
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches ErrSourceNotFound because the source is a missing file.
+func (e *ProviderError) Is(target error) bool {
+	return target == ErrSourceNotFound && errors.Is(e.Err, fs.ErrNotExist)
+}
+
+// DecodeError is returned when data provided by a source can't be decoded.
+type DecodeError struct {
+	// Source is the name of the provider (see SourceNamer), empty if it's unknown.
+	Source string
+	// Path to the field that can't be decoded, e.g. "db.port". Empty if it's unknown,
+	// e.g. for syntax errors.
+	Path string
+	// Line and Column of the error in the data starting from 1, 0 if they are unknown.
+	// For JSON type errors it's the position of the end of the value.
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error implements error.
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	b.WriteString("decode config")
+	if e.Source != "" {
+		fmt.Fprintf(&b, " from '%s'", e.Source)
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, " at line %d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ", column %d", e.Column)
+		}
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&b, ": field '%s'", e.Path)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+
+	return b.String()
+}
+
+// Unwrap returns the error of the decoder.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ReferenceError is returned when a reference in a string field can't be resolved
+// (see WithReferences).
+type ReferenceError struct {
+	// Reference as it's written in the value, e.g. "${vault:secret/db#password}".
+	Reference string
+	// Path to the field holding the reference, e.g. "db.password".
+	Path string
+	Err  error
+}
+
+// Error implements error.
+func (e *ReferenceError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("resolve reference '%s': %v", e.Reference, e.Err)
+	}
+
+	return fmt.Sprintf("resolve reference '%s' of field '%s': %v", e.Reference, e.Path, e.Err)
+}
+
+// Unwrap returns the error of the resolver.
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// newProviderError wraps err of provider into ProviderError.
+func newProviderError(provider ConfigProvider, err error) *ProviderError {
+	return &ProviderError{Source: sourceName(provider), Err: err}
+}
+
+// newDecodeError wraps err of decoder into DecodeError. Position of the error is found
+// in err, data is the decoded data used to convert offsets to lines and columns.
+func newDecodeError(source string, data []byte, err error) *DecodeError {
+	var decErr *DecodeError
+	if errors.As(err, &decErr) {
+		return &DecodeError{Source: source, Path: decErr.Path, Line: decErr.Line, Column: decErr.Column, Err: err}
+	}
+
+	decErr = &DecodeError{Source: source, Err: err}
+
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		tomlParseErr toml.ParseError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		decErr.Line, decErr.Column = offsetPosition(data, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		decErr.Path = typeErr.Field
+		decErr.Line, decErr.Column = offsetPosition(data, typeErr.Offset)
+	case errors.As(err, &tomlParseErr):
+		decErr.Line, decErr.Column = tomlParseErr.Position.Line, tomlParseErr.Position.Col
+	default:
+		// yaml.v3 and other decoders report the position only in messages.
+		if m := linePattern.FindStringSubmatch(err.Error()); m != nil {
+			decErr.Line, _ = strconv.Atoi(m[1])
+			decErr.Column, _ = strconv.Atoi(m[2])
+		}
+	}
+
+	return decErr
+}
+
+var linePattern = regexp.MustCompile(`\bline (\d+)(?:[:,] column (\d+))?`)
+
+// offsetPosition returns line and column starting from 1 of the last byte read by json decoder,
+// which reports offsets after the byte that caused the error. It returns zeros if offset is out
+// of data.
+func offsetPosition(data []byte, offset int64) (line, column int) {
+	if offset <= 0 || offset > int64(len(data)) {
+		return 0, 0
+	}
+
+	before := data[:offset-1]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - bytes.LastIndexByte(before, '\n')
+
+	return line, column
+}
@@ -0,0 +1,137 @@
+package config_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testErrorsConfig struct {
+	Name string `json:"name" yaml:"name" toml:"name"`
+	DB   struct {
+		Port int `json:"port" yaml:"port" toml:"port"`
+	} `json:"db" yaml:"db" toml:"db"`
+}
+
+func TestProviderError(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	_, err := config.New[testErrorsConfig](config.FromFile(missing))
+
+	var provErr *config.ProviderError
+	require.ErrorAs(t, err, &provErr)
+	require.Equal(t, "file:"+missing, provErr.Source)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.ErrorIs(t, err, config.ErrSourceNotFound)
+	require.ErrorContains(t, err, "provide config from 'file:"+missing+"'")
+
+	var decErr *config.DecodeError
+	require.False(t, errors.As(err, &decErr))
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		dec  func(r io.Reader) config.Decoder
+		exp  config.DecodeError
+	}{
+		{
+			name: "json syntax",
+			data: "{\n  \"name\": \"svc\",\n  \"db\": {\"port\": }\n}",
+			dec:  config.DecoderWrap(config.JsonDecoder),
+			exp:  config.DecodeError{Line: 3, Column: 18},
+		},
+		{
+			name: "json type",
+			data: "{\n  \"db\": {\"port\": \"abc\"}\n}",
+			dec:  config.DecoderWrap(config.JsonDecoder),
+			exp:  config.DecodeError{Path: "db.port", Line: 2, Column: 22},
+		},
+		{
+			name: "yaml",
+			data: "name: svc\ndb:\n  port: abc\n",
+			dec:  config.DecoderWrap(config.YamlDecoder),
+			exp:  config.DecodeError{Line: 3},
+		},
+		{
+			name: "toml",
+			data: "name = \"svc\"\n[db]\nport = =\n",
+			dec:  config.DecoderWrap(config.TomlDecoder),
+			exp:  config.DecodeError{Line: 3, Column: 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.New[testErrorsConfig](config.FromReader(strings.NewReader(tt.data)), config.WithDecoder(tt.dec))
+
+			var decErr *config.DecodeError
+			require.ErrorAs(t, err, &decErr)
+			require.Equal(t, tt.exp.Path, decErr.Path)
+			require.Equal(t, tt.exp.Line, decErr.Line)
+			require.Equal(t, tt.exp.Column, decErr.Column)
+
+			var provErr *config.ProviderError
+			require.False(t, errors.As(err, &provErr))
+		})
+	}
+}
+
+func TestDecodeError_Source(t *testing.T) {
+	path := writeFile(t, "config.json", `{"db": {"port": "abc"}}`)
+
+	var cfg testErrorsConfig
+	err := config.Fill(&cfg, config.FromFile(path))
+
+	var decErr *config.DecodeError
+	require.ErrorAs(t, err, &decErr)
+	require.Equal(t, "file:"+path, decErr.Source)
+	require.ErrorContains(t, err, "decode config from 'file:"+path+"' at line 1, column 21: field 'db.port'")
+
+	_, err = config.Multi[testErrorsConfig]().
+		Add(config.FromReader(strings.NewReader(`{"name": "svc"}`))).
+		Add(config.FromFile(path)).
+		AllOf()
+	require.ErrorAs(t, err, &decErr)
+	require.Equal(t, "db.port", decErr.Path)
+
+	_, err = config.New[testErrorsConfig](
+		config.FromReader(strings.NewReader("name: [")),
+		config.WithDecoder(config.FallbackDecoder(config.DecoderWrap(config.JsonDecoder), config.DecoderWrap(config.YamlDecoder))),
+	)
+	require.ErrorAs(t, err, &decErr)
+}
+
+func TestFallbackProvider_NotFound(t *testing.T) {
+	dir := t.TempDir()
+	missing := config.FromFile(filepath.Join(dir, "missing.json"))
+
+	_, err := config.New[testErrorsConfig](config.FallbackProvider(missing, config.FromFile(filepath.Join(dir, "other.json"))))
+	require.ErrorIs(t, err, config.ErrSourceNotFound)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = config.New[testErrorsConfig](config.FallbackProvider(missing, &errProvider{}))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, os.ErrNotExist)
+	require.NotErrorIs(t, err, config.ErrSourceNotFound)
+
+	// Errors of missing sources are kept in the chain.
+	_, err = config.FallbackProvider(missing, &errProvider{}).ProvideConfig()
+	require.NotErrorIs(t, err, config.ErrSourceNotFound)
+
+	var provErr *config.ProviderError
+	require.ErrorAs(t, err, &provErr)
+	require.Equal(t, "file:"+filepath.Join(dir, "missing.json"), provErr.Source)
+
+	var pathErr *fs.PathError
+	require.ErrorAs(t, err, &pathErr)
+	require.Equal(t, filepath.Join(dir, "missing.json"), pathErr.Path)
+}
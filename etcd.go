@@ -0,0 +1,376 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// DefaultEtcdDialTimeout is the default timeout of connecting to etcd for the default client.
+	DefaultEtcdDialTimeout = 5 * time.Second
+
+	defaultEtcdEndpoint   = "localhost:2379"
+	defaultEtcdMinBackoff = time.Second
+	defaultEtcdMaxBackoff = time.Minute
+)
+
+var (
+	_ ConfigProvider        = &etcdProvider{}
+	_ ContextConfigProvider = &etcdProvider{}
+	_ WatchableProvider     = &etcdProvider{}
+	_ SourceNamer           = &etcdProvider{}
+)
+
+type EtcdOption func(*etcdOpts) error
+
+type etcdOpts struct {
+	client      *clientv3.Client
+	endpoints   []string
+	tls         *tls.Config
+	username    string
+	password    string
+	dialTimeout time.Duration
+	revision    int64
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+
+	typedValues  bool
+	keyTransform func(segment string) string
+}
+
+// etcdBase holds etcd client and options shared by etcd providers.
+type etcdBase struct {
+	client   *clientv3.Client
+	opts     etcdOpts
+	funcOpts []EtcdOption
+}
+
+type etcdProvider struct {
+	etcdBase
+	key string
+}
+
+func (e *etcdBase) lazyInit() error {
+	if e.client != nil {
+		return nil
+	}
+
+	etcdOpts := etcdOpts{
+		endpoints:   []string{defaultEtcdEndpoint},
+		dialTimeout: DefaultEtcdDialTimeout,
+		minBackoff:  defaultEtcdMinBackoff,
+		maxBackoff:  defaultEtcdMaxBackoff,
+	}
+	for _, option := range e.funcOpts {
+		if option == nil {
+			continue
+		}
+
+		if err := option(&etcdOpts); err != nil {
+			return fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	if etcdOpts.client == nil {
+		var err error
+		etcdOpts.client, err = clientv3.New(clientv3.Config{
+			Endpoints:   etcdOpts.endpoints,
+			TLS:         etcdOpts.tls,
+			Username:    etcdOpts.username,
+			Password:    etcdOpts.password,
+			DialTimeout: etcdOpts.dialTimeout,
+		})
+		if err != nil {
+			return fmt.Errorf("create etcd client: %w", err)
+		}
+	}
+
+	e.client = etcdOpts.client
+	e.opts = etcdOpts
+
+	return nil
+}
+
+// getOptions returns options of get requests.
+func (e *etcdBase) getOptions(opts ...clientv3.OpOption) []clientv3.OpOption {
+	if e.opts.revision > 0 {
+		opts = append(opts, clientv3.WithRev(e.opts.revision))
+	}
+
+	return opts
+}
+
+// Source implements SourceNamer.
+func (e *etcdProvider) Source() string {
+	return "etcd:" + e.key
+}
+
+// ProvideConfig implements ConfigProvider.
+func (e *etcdProvider) ProvideConfig() (io.Reader, error) {
+	return e.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is passed to the request.
+func (e *etcdProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	if err := e.lazyInit(); err != nil {
+		return nil, fmt.Errorf("init lazy: %w", err)
+	}
+
+	value, _, err := e.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, fmt.Errorf("etcd get: key '%s' doesn't exist: %w", e.key, ErrSourceNotFound)
+	}
+
+	return bytes.NewReader(value), nil
+}
+
+// get returns the value of the key and the revision of the store. Value is nil if the key
+// doesn't exist.
+func (e *etcdProvider) get(ctx context.Context) ([]byte, int64, error) {
+	resp, err := e.client.Get(ctx, e.key, e.getOptions()...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("etcd get: %w", err)
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, resp.Header.GetRevision(), nil
+	}
+
+	return resp.Kvs[0].Value, resp.Header.GetRevision(), nil
+}
+
+// Watch implements WatchableProvider.
+//
+// It uses etcd watch to get notified as soon as the key is modified. Deletion of the key isn't
+// notified, the last provided config stays actual until the key appears again. Broken watches
+// are restarted with exponential backoff. Watching isn't supported if the revision is pinned.
+func (e *etcdProvider) Watch(ctx context.Context) (<-chan struct{}, error) {
+	if err := e.lazyInit(); err != nil {
+		return nil, fmt.Errorf("init lazy: %w", err)
+	}
+
+	if e.opts.revision > 0 {
+		return nil, errors.New("etcd watch: revision is pinned")
+	}
+
+	return watchEtcd(ctx, e.client, e.opts, e.key, false, e.get), nil
+}
+
+// etcdQuery returns the watched value and the revision of the store.
+type etcdQuery func(ctx context.Context) ([]byte, int64, error)
+
+type etcdWatch struct {
+	client   *clientv3.Client
+	opts     etcdOpts
+	key      string
+	prefix   bool
+	query    etcdQuery
+	notify   chan struct{}
+	revision int64
+	last     []byte
+}
+
+// watchEtcd watches the key (or keys under the prefix) in background until ctx is done and
+// notifies when the value returned by query changes. The initial value is queried synchronously,
+// so changes made after the call aren't missed.
+func watchEtcd(ctx context.Context, client *clientv3.Client, opts etcdOpts, key string, prefix bool, query etcdQuery) <-chan struct{} {
+	w := &etcdWatch{
+		client: client,
+		opts:   opts,
+		key:    key,
+		prefix: prefix,
+		query:  query,
+		notify: make(chan struct{}, 1),
+	}
+
+	if value, revision, err := query(ctx); err == nil {
+		w.last, w.revision = value, revision
+	}
+
+	go w.run(ctx)
+	return w.notify
+}
+
+func (w *etcdWatch) run(ctx context.Context) {
+	defer close(w.notify)
+
+	backoff := w.opts.minBackoff
+	for ctx.Err() == nil {
+		if w.watch(ctx) {
+			backoff = w.opts.minBackoff
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, w.opts.maxBackoff)
+	}
+}
+
+// watch watches changes since the last seen revision until the watch breaks.
+// It reports whether the watch received events, so it can be restarted without backoff.
+func (w *etcdWatch) watch(ctx context.Context) bool {
+	watchCtx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+
+	opts := []clientv3.OpOption{clientv3.WithRev(w.revision + 1)}
+	if w.prefix {
+		opts = append(opts, clientv3.WithPrefix())
+	}
+
+	received := false
+	for resp := range w.client.Watch(watchCtx, w.key, opts...) {
+		if resp.CompactRevision > 0 {
+			// Missed revisions were compacted, so the value is queried again.
+			w.revision = 0
+			w.refresh(ctx)
+			return received
+		}
+
+		if err := resp.Err(); err != nil {
+			return received
+		}
+
+		received = true
+		if len(resp.Events) > 0 {
+			w.refresh(ctx)
+		}
+		w.revision = max(w.revision, resp.Header.GetRevision())
+	}
+
+	return received
+}
+
+// refresh queries the value and notifies if it changed.
+func (w *etcdWatch) refresh(ctx context.Context) {
+	value, revision, err := w.query(ctx)
+	if err != nil {
+		return
+	}
+
+	w.revision = max(w.revision, revision)
+	if value == nil || bytes.Equal(value, w.last) {
+		return
+	}
+
+	w.last = value
+	trySend(w.notify)
+}
+
+// Overrides etcd client.
+func EtcdWithClient(client *clientv3.Client) EtcdOption {
+	return func(v *etcdOpts) error {
+		if client == nil {
+			return errors.New("got nil etcd client")
+		}
+
+		v.client = client
+		return nil
+	}
+}
+
+// Defines endpoints of etcd cluster for the default client. By default, localhost:2379 is used.
+func EtcdWithEndpoints(endpoints ...string) EtcdOption {
+	return func(v *etcdOpts) error {
+		if len(endpoints) == 0 {
+			return errors.New("got no etcd endpoints")
+		}
+
+		v.endpoints = endpoints
+		return nil
+	}
+}
+
+// Defines TLS config of the default client.
+func EtcdWithTLS(cfg *tls.Config) EtcdOption {
+	return func(v *etcdOpts) error {
+		if cfg == nil {
+			return errors.New("got nil tls config")
+		}
+
+		v.tls = cfg
+		return nil
+	}
+}
+
+// Defines credentials of the default client.
+func EtcdWithAuth(username, password string) EtcdOption {
+	return func(v *etcdOpts) error {
+		if username == "" {
+			return errors.New("empty etcd username")
+		}
+
+		v.username = username
+		v.password = password
+		return nil
+	}
+}
+
+// Defines timeout of connecting to etcd for the default client.
+// By default, DefaultEtcdDialTimeout is used.
+func EtcdWithDialTimeout(d time.Duration) EtcdOption {
+	return func(v *etcdOpts) error {
+		if d <= 0 {
+			return fmt.Errorf("dial timeout must be positive, got %s", d)
+		}
+
+		v.dialTimeout = d
+		return nil
+	}
+}
+
+// Pins the revision of etcd store that config is read at. Providers with pinned revision
+// can't be watched.
+func EtcdWithRevision(revision int64) EtcdOption {
+	return func(v *etcdOpts) error {
+		if revision <= 0 {
+			return fmt.Errorf("revision must be positive, got %d", revision)
+		}
+
+		v.revision = revision
+		return nil
+	}
+}
+
+// Defines the bounds of exponential backoff between restarts of broken watches used by Watch.
+// By default, backoff starts from 1 second and grows up to 1 minute.
+func EtcdWithBackoff(minBackoff, maxBackoff time.Duration) EtcdOption {
+	return func(v *etcdOpts) error {
+		if minBackoff <= 0 || maxBackoff < minBackoff {
+			return fmt.Errorf("invalid backoff bounds [%s, %s]", minBackoff, maxBackoff)
+		}
+
+		v.minBackoff = minBackoff
+		v.maxBackoff = maxBackoff
+		return nil
+	}
+}
+
+// Returns config provider that provides config from etcd key.
+//
+// If client wasn't passed with options, it's created with endpoints, TLS config and
+// credentials from options.
+//
+// The provider implements WatchableProvider, so it can be used with Watch to reload config
+// when the key is modified.
+func FromEtcd(key string, opts ...EtcdOption) *etcdProvider {
+	return &etcdProvider{
+		etcdBase: etcdBase{funcOpts: opts},
+		key:      key,
+	}
+}
@@ -0,0 +1,196 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// newEmbedEtcd starts a single-node etcd server for the test and returns its client.
+func newEmbedEtcd(t testing.TB) *clientv3.Client {
+	t.Helper()
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+
+	clientURL, peerURL := freeURL(t), freeURL(t)
+	cfg.ListenClientUrls, cfg.AdvertiseClientUrls = []url.URL{clientURL}, []url.URL{clientURL}
+	cfg.ListenPeerUrls, cfg.AdvertisePeerUrls = []url.URL{peerURL}, []url.URL{peerURL}
+	cfg.InitialCluster = fmt.Sprintf("%s=%s", cfg.Name, peerURL.String())
+
+	srv, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	select {
+	case <-srv.Server.ReadyNotify():
+	case err := <-srv.Err():
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("etcd server isn't ready")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{clientURL.String()},
+		DialTimeout: 5 * time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+// freeURL returns URL of a free local port.
+func freeURL(t testing.TB) url.URL {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	return url.URL{Scheme: "http", Host: l.Addr().String()}
+}
+
+func TestEtcdProvider_Embedded(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newEmbedEtcd(t)
+	put := func(key, value string) int64 {
+		t.Helper()
+
+		resp, err := client.Put(ctx, key, value)
+		r.NoError(err)
+		return resp.Header.Revision
+	}
+
+	rev := put("foo/bar", `{"foo": "bar"}`)
+	put("foo/bar", `{"foo": "buz"}`)
+
+	cfg, err := config.New[testConfig](config.FromEtcd("foo/bar", config.EtcdWithClient(client)))
+	r.NoError(err)
+	r.Equal(testConfig{Foo: "buz"}, cfg)
+
+	cfg, err = config.New[testConfig](config.FromEtcd("foo/bar",
+		config.EtcdWithClient(client),
+		config.EtcdWithRevision(rev),
+	))
+	r.NoError(err)
+	r.Equal(testConfig{Foo: "bar"}, cfg)
+
+	_, err = config.New[testConfig](config.FromEtcd("foo", config.EtcdWithClient(client)))
+	r.ErrorIs(err, config.ErrSourceNotFound)
+
+	w, err := config.Watch[testConfig](ctx, config.FromEtcd("foo/bar",
+		config.EtcdWithClient(client),
+		config.EtcdWithBackoff(time.Millisecond, 10*time.Millisecond),
+	))
+	r.NoError(err)
+	r.Equal(testConfig{Foo: "buz"}, w.Config())
+
+	requireChange := func(expected testConfig) {
+		t.Helper()
+
+		select {
+		case change := <-w.Changes():
+			r.Equal(expected, change.New)
+		case <-time.After(5 * time.Second):
+			r.FailNow("no change")
+		}
+	}
+
+	put("foo/bar", `{"foo": "watched"}`)
+	requireChange(testConfig{Foo: "watched"})
+
+	// Keys sharing the prefix don't lead to reload.
+	put("foo/bar2", `{"foo": "other"}`)
+	select {
+	case change := <-w.Changes():
+		r.FailNow("unexpected change", change)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Deleted key is not notified.
+	_, err = client.Delete(ctx, "foo/bar")
+	r.NoError(err)
+	rev = put("foo/bar", `{"foo": "recreated"}`)
+	requireChange(testConfig{Foo: "recreated"})
+
+	// Compaction of watched history doesn't break the watch.
+	_, err = client.Compact(ctx, rev)
+	r.NoError(err)
+	put("foo/bar", `{"foo": "after compaction"}`)
+	requireChange(testConfig{Foo: "after compaction"})
+}
+
+func TestEtcdPrefixProvider_Embedded(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newEmbedEtcd(t)
+	for key, value := range map[string]string{
+		"service/name":       "foo",
+		"service/db/host":    "localhost",
+		"service/db/tls":     "true",
+		"service-other/name": "bar",
+	} {
+		_, err := client.Put(ctx, key, value)
+		r.NoError(err)
+	}
+
+	provider := config.FromEtcdPrefix("service",
+		config.EtcdWithClient(client),
+		config.EtcdWithValueTyping(),
+	)
+
+	cfg, err := config.New[testConsulPrefixConfig](provider)
+	r.NoError(err)
+	r.Equal("foo", cfg.Name)
+	r.Equal("localhost", cfg.DB.Host)
+	r.True(cfg.DB.TLS)
+
+	_, err = config.New[testConsulPrefixConfig](config.FromEtcdPrefix("missing", config.EtcdWithClient(client)))
+	r.ErrorIs(err, config.ErrSourceNotFound)
+
+	w, err := config.Watch[testConsulPrefixConfig](ctx, provider)
+	r.NoError(err)
+
+	requireChange := func(check func(cfg testConsulPrefixConfig)) {
+		t.Helper()
+
+		select {
+		case change := <-w.Changes():
+			check(change.New)
+		case <-time.After(5 * time.Second):
+			r.FailNow("no change")
+		}
+	}
+
+	_, err = client.Put(ctx, "service/db/port", "5432")
+	r.NoError(err)
+	requireChange(func(cfg testConsulPrefixConfig) { r.Equal(5432, cfg.DB.Port) })
+
+	// Keys of other prefixes don't lead to reload.
+	_, err = client.Put(ctx, "service-other/name", "buz")
+	r.NoError(err)
+	select {
+	case change := <-w.Changes():
+		r.FailNow("unexpected change", change)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	_, err = client.Delete(ctx, "service/db/", clientv3.WithPrefix())
+	r.NoError(err)
+	requireChange(func(cfg testConsulPrefixConfig) { r.Empty(cfg.DB) })
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var (
+	_ ConfigProvider        = &etcdPrefixProvider{}
+	_ ContextConfigProvider = &etcdPrefixProvider{}
+	_ WatchableProvider     = &etcdPrefixProvider{}
+	_ SourceNamer           = &etcdPrefixProvider{}
+)
+
+type etcdPrefixProvider struct {
+	etcdBase
+	prefix string
+}
+
+// Source implements SourceNamer.
+func (e *etcdPrefixProvider) Source() string {
+	return "etcd-prefix:" + e.prefix
+}
+
+// ProvideConfig implements ConfigProvider.
+func (e *etcdPrefixProvider) ProvideConfig() (io.Reader, error) {
+	return e.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is passed to the request.
+func (e *etcdPrefixProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	if err := e.lazyInit(); err != nil {
+		return nil, fmt.Errorf("init lazy: %w", err)
+	}
+
+	data, _, err := e.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, fmt.Errorf("etcd list: prefix '%s' doesn't contain keys: %w", e.prefix, ErrSourceNotFound)
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// Watch implements WatchableProvider.
+//
+// It uses etcd watch to get notified as soon as any key under the prefix is modified.
+// Broken watches are restarted with exponential backoff. Watching isn't supported if
+// the revision is pinned.
+func (e *etcdPrefixProvider) Watch(ctx context.Context) (<-chan struct{}, error) {
+	if err := e.lazyInit(); err != nil {
+		return nil, fmt.Errorf("init lazy: %w", err)
+	}
+
+	if e.opts.revision > 0 {
+		return nil, errors.New("etcd watch: revision is pinned")
+	}
+
+	return watchEtcd(ctx, e.client, e.opts, e.keyPrefix(), true, e.list), nil
+}
+
+// keyPrefix returns the prefix with trailing "/", so keys with the same beginning
+// (e.g. "service-old" for "service") aren't listed.
+func (e *etcdPrefixProvider) keyPrefix() string {
+	prefix := e.prefix
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return prefix
+}
+
+// list lists keys under the prefix and assembles them into JSON document.
+// It returns nil document if there are no keys.
+func (e *etcdPrefixProvider) list(ctx context.Context) ([]byte, int64, error) {
+	prefix := e.keyPrefix()
+
+	resp, err := e.client.Get(ctx, prefix, e.getOptions(clientv3.WithPrefix())...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("etcd list: %w", err)
+	}
+
+	revision := resp.Header.GetRevision()
+	tree := map[string]any{}
+	found := false
+	for _, kv := range resp.Kvs {
+		key := strings.TrimPrefix(string(kv.Key), prefix)
+		if key == "" || strings.HasSuffix(key, "/") {
+			// Folder.
+			continue
+		}
+
+		if err := insertTreeKey(tree, key, kv.Value, e.opts.typedValues, e.opts.keyTransform); err != nil {
+			return nil, revision, fmt.Errorf("assemble key '%s': %w", kv.Key, err)
+		}
+		found = true
+	}
+
+	if !found {
+		return nil, revision, nil
+	}
+
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil, revision, fmt.Errorf("encode config: %w", err)
+	}
+
+	return data, revision, nil
+}
+
+// Makes FromEtcdPrefix convert values into booleans ("true", "false"), numbers and JSON
+// objects or arrays when possible. By default, all values are strings.
+func EtcdWithValueTyping() EtcdOption {
+	return func(v *etcdOpts) error {
+		v.typedValues = true
+		return nil
+	}
+}
+
+// Defines the function that FromEtcdPrefix applies to every key segment before
+// using it as object key, e.g. strings.ToLower.
+func EtcdWithKeyTransform(transform func(segment string) string) EtcdOption {
+	return func(v *etcdOpts) error {
+		if transform == nil {
+			return errors.New("got nil key transform")
+		}
+
+		v.keyTransform = transform
+		return nil
+	}
+}
+
+// Returns config provider that assembles config from all etcd keys under prefix.
+// Keys are split by "/" into nested JSON objects, so it can be decoded with the default decoder.
+//
+// Example:
+//
+//	// etcd
+//	service/db/host = localhost
+//	service/db/port = 5432
+//	// FromEtcdPrefix("service", EtcdWithValueTyping()) provides
+//	{"db": {"host": "localhost", "port": 5432}}
+//
+// If client wasn't passed with options, it's created with endpoints, TLS config and
+// credentials from options.
+//
+// The provider implements WatchableProvider, so it can be used with Watch to reload config
+// when any key under the prefix is modified.
+func FromEtcdPrefix(prefix string, opts ...EtcdOption) *etcdPrefixProvider {
+	return &etcdPrefixProvider{
+		etcdBase: etcdBase{funcOpts: opts},
+		prefix:   prefix,
+	}
+}
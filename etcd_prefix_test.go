@@ -0,0 +1,118 @@
+package config_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtcdPrefixProvider(t *testing.T) {
+	fake, client := newFakeEtcd(t)
+	fake.put("service/", []byte{})
+	fake.put("service/name", []byte("foo"))
+	fake.put("service/db/host", []byte("localhost"))
+	fake.put("service/db/port", []byte("5432"))
+	fake.put("service/db/tls", []byte("true"))
+	fake.put("service/db/options", []byte(`{"timeout": "1s"}`))
+	fake.put("service-other/name", []byte("bar"))
+
+	t.Run("Strings", func(t *testing.T) {
+		r := require.New(t)
+
+		dataReader, err := config.FromEtcdPrefix("service", config.EtcdWithClient(client)).ProvideConfig()
+		r.NoError(err)
+
+		data, err := io.ReadAll(dataReader)
+		r.NoError(err)
+		r.JSONEq(`{
+			"name": "foo",
+			"db": {"host": "localhost", "port": "5432", "tls": "true", "options": "{\"timeout\": \"1s\"}"}
+		}`, string(data))
+	})
+
+	t.Run("TypedValues", func(t *testing.T) {
+		r := require.New(t)
+
+		cfg, err := config.New[testConsulPrefixConfig](config.FromEtcdPrefix("service/",
+			config.EtcdWithClient(client),
+			config.EtcdWithValueTyping(),
+		))
+		r.NoError(err)
+		r.Equal("foo", cfg.Name)
+		r.Equal("localhost", cfg.DB.Host)
+		r.Equal(5432, cfg.DB.Port)
+		r.True(cfg.DB.TLS)
+		r.Equal(map[string]any{"timeout": "1s"}, cfg.DB.Options)
+	})
+
+	t.Run("Revision", func(t *testing.T) {
+		r := require.New(t)
+
+		cfg, err := config.New[testConsulPrefixConfig](config.FromEtcdPrefix("service",
+			config.EtcdWithClient(client),
+			config.EtcdWithRevision(4),
+		))
+		r.NoError(err)
+		r.Equal("localhost", cfg.DB.Host)
+		r.Empty(cfg.DB.Port)
+	})
+
+	t.Run("KeyTransform", func(t *testing.T) {
+		r := require.New(t)
+		fake.put("upper/DB/HOST", []byte("localhost"))
+
+		cfg, err := config.New[testConsulPrefixConfig](config.FromEtcdPrefix("upper",
+			config.EtcdWithClient(client),
+			config.EtcdWithKeyTransform(strings.ToLower),
+		))
+		r.NoError(err)
+		r.Equal("localhost", cfg.DB.Host)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		fake.put("conflict/db", []byte("value"))
+		fake.put("conflict/db/host", []byte("localhost"))
+
+		_, err := config.FromEtcdPrefix("conflict", config.EtcdWithClient(client)).ProvideConfig()
+		require.Error(t, err)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := config.FromEtcdPrefix("missing", config.EtcdWithClient(client)).ProvideConfig()
+		require.ErrorIs(t, err, config.ErrSourceNotFound)
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		_, err := config.FromEtcdPrefix("service", config.EtcdWithKeyTransform(nil)).ProvideConfig()
+		require.Error(t, err)
+	})
+}
+
+func TestEtcdPrefixProvider_Watch(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake, client := newFakeEtcd(t)
+	fake.put("service/db/host", []byte("localhost"))
+
+	w, err := config.Watch[testConsulPrefixConfig](ctx, config.FromEtcdPrefix("service",
+		config.EtcdWithClient(client),
+		config.EtcdWithValueTyping(),
+	))
+	r.NoError(err)
+	r.Equal("localhost", w.Config().DB.Host)
+
+	fake.put("service/db/port", []byte("5432"))
+	select {
+	case change := <-w.Changes():
+		r.Equal(5432, change.New.DB.Port)
+	case <-time.After(time.Second):
+		r.FailNow("no change")
+	}
+}
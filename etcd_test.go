@@ -0,0 +1,278 @@
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// fakeEtcd is an in-memory stand-in of etcd KV and Watch API that keeps history of revisions.
+type fakeEtcd struct {
+	clientv3.KV
+	clientv3.Watcher
+
+	mu        sync.Mutex
+	events    []*clientv3.Event
+	compacted int64
+	watches   map[*fakeEtcdWatch]struct{}
+}
+
+type fakeEtcdWatch struct {
+	key, end []byte
+	ch       chan clientv3.WatchResponse
+}
+
+func newFakeEtcd(t testing.TB) (*fakeEtcd, *clientv3.Client) {
+	f := &fakeEtcd{watches: map[*fakeEtcdWatch]struct{}{}}
+	t.Cleanup(f.breakWatches)
+
+	return f, &clientv3.Client{KV: f, Watcher: f}
+}
+
+func (f *fakeEtcd) revision() int64 {
+	return int64(len(f.events)) + 1
+}
+
+// put sets key at the next revision. Nil value deletes the key.
+func (f *fakeEtcd) put(key string, value []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ev := &clientv3.Event{
+		Type: mvccpb.PUT,
+		Kv:   &mvccpb.KeyValue{Key: []byte(key), Value: value, ModRevision: f.revision() + 1},
+	}
+	if value == nil {
+		ev.Type = mvccpb.DELETE
+	}
+	f.events = append(f.events, ev)
+
+	for w := range f.watches {
+		if w.matches(ev.Kv.Key) {
+			w.ch <- clientv3.WatchResponse{
+				Header: pb.ResponseHeader{Revision: f.revision()},
+				Events: []*clientv3.Event{ev},
+			}
+		}
+	}
+}
+
+// compact drops history before the current revision.
+func (f *fakeEtcd) compact() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.compacted = f.revision()
+}
+
+// breakWatches cancels all watches like etcd does on failures.
+func (f *fakeEtcd) breakWatches() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for w := range f.watches {
+		w.ch <- clientv3.WatchResponse{Canceled: true}
+		close(w.ch)
+		delete(f.watches, w)
+	}
+}
+
+func (w *fakeEtcdWatch) matches(key []byte) bool {
+	if w.end == nil {
+		return bytes.Equal(key, w.key)
+	}
+
+	return bytes.Compare(key, w.key) >= 0 && bytes.Compare(key, w.end) < 0
+}
+
+func (f *fakeEtcd) Get(_ context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	op := clientv3.OpGet(key, opts...)
+	rev := op.Rev()
+	if rev == 0 {
+		rev = f.revision()
+	}
+
+	w := &fakeEtcdWatch{key: []byte(key), end: op.RangeBytes()}
+	state := map[string]*mvccpb.KeyValue{}
+	for _, ev := range f.events[:rev-1] {
+		if !w.matches(ev.Kv.Key) {
+			continue
+		}
+
+		if ev.Type == mvccpb.DELETE {
+			delete(state, string(ev.Kv.Key))
+		} else {
+			state[string(ev.Kv.Key)] = ev.Kv
+		}
+	}
+
+	resp := &clientv3.GetResponse{Header: &pb.ResponseHeader{Revision: f.revision()}}
+	for _, kv := range state {
+		resp.Kvs = append(resp.Kvs, kv)
+	}
+
+	return resp, nil
+}
+
+func (f *fakeEtcd) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	op := clientv3.OpGet(key, opts...)
+	w := &fakeEtcdWatch{key: []byte(key), end: op.RangeBytes(), ch: make(chan clientv3.WatchResponse, 64)}
+
+	if op.Rev() <= f.compacted {
+		w.ch <- clientv3.WatchResponse{Canceled: true, CompactRevision: f.compacted}
+		close(w.ch)
+		return w.ch
+	}
+
+	// Event of revision r is at index r-2, as the first revision is 1.
+	for _, ev := range f.events[min(max(op.Rev()-2, 0), int64(len(f.events))):] {
+		if w.matches(ev.Kv.Key) {
+			w.ch <- clientv3.WatchResponse{
+				Header: pb.ResponseHeader{Revision: f.revision()},
+				Events: []*clientv3.Event{ev},
+			}
+		}
+	}
+
+	f.watches[w] = struct{}{}
+	go func() {
+		<-ctx.Done()
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if _, ok := f.watches[w]; ok {
+			close(w.ch)
+			delete(f.watches, w)
+		}
+	}()
+
+	return w.ch
+}
+
+func TestEtcdProvider(t *testing.T) {
+	fake, client := newFakeEtcd(t)
+	fake.put("foo/bar", []byte(`{"foo": "bar"}`))
+	fake.put("foo/bar", []byte(`{"foo": "buz"}`))
+
+	t.Run("Latest", func(t *testing.T) {
+		r := require.New(t)
+
+		dataReader, err := config.FromEtcd("foo/bar", config.EtcdWithClient(client)).ProvideConfig()
+		r.NoError(err)
+
+		data, err := io.ReadAll(dataReader)
+		r.NoError(err)
+		r.Equal([]byte(`{"foo": "buz"}`), data)
+	})
+
+	t.Run("Revision", func(t *testing.T) {
+		cfg, err := config.New[testConfig](config.FromEtcd("foo/bar",
+			config.EtcdWithClient(client),
+			config.EtcdWithRevision(2),
+		))
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "bar"}, cfg)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := config.New[testConfig](config.FromEtcd("foo/missing", config.EtcdWithClient(client)))
+		require.ErrorIs(t, err, config.ErrSourceNotFound)
+		require.ErrorContains(t, err, "etcd:foo/missing")
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		for _, opt := range []config.EtcdOption{
+			config.EtcdWithClient(nil),
+			config.EtcdWithEndpoints(),
+			config.EtcdWithTLS(nil),
+			config.EtcdWithAuth("", "password"),
+			config.EtcdWithDialTimeout(0),
+			config.EtcdWithRevision(0),
+			config.EtcdWithBackoff(time.Second, time.Millisecond),
+		} {
+			_, err := config.FromEtcd("foo/bar", opt).ProvideConfig()
+			require.Error(t, err)
+		}
+	})
+}
+
+func TestEtcdProvider_Watch(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fake, client := newFakeEtcd(t)
+	fake.put("foo/bar", []byte(`{"foo": "bar"}`))
+
+	w, err := config.Watch[testConfig](ctx, config.FromEtcd("foo/bar",
+		config.EtcdWithClient(client),
+		config.EtcdWithBackoff(time.Millisecond, 10*time.Millisecond),
+	))
+	r.NoError(err)
+	r.Equal(testConfig{Foo: "bar"}, w.Config())
+
+	requireChange := func(expected testConfig) {
+		t.Helper()
+
+		select {
+		case change := <-w.Changes():
+			r.Equal(expected, change.New)
+		case <-time.After(time.Second):
+			r.FailNow("no change")
+		}
+	}
+
+	fake.put("foo/bar", []byte(`{"foo": "buz"}`))
+	requireChange(testConfig{Foo: "buz"})
+
+	// Unrelated modification doesn't lead to reload.
+	fake.put("foo/other", []byte(`{}`))
+	select {
+	case change := <-w.Changes():
+		r.FailNow("unexpected change", change)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Broken watch is restarted, changes made meanwhile aren't missed.
+	fake.breakWatches()
+	fake.put("foo/bar", []byte(`{"foo": "after break"}`))
+	requireChange(testConfig{Foo: "after break"})
+
+	// Compacted history.
+	fake.breakWatches()
+	fake.put("foo/bar", []byte(`{"foo": "after compaction"}`))
+	fake.compact()
+	requireChange(testConfig{Foo: "after compaction"})
+
+	// Deleted key is not notified.
+	fake.put("foo/bar", nil)
+	fake.put("foo/bar", []byte(`{"foo": "recreated"}`))
+	requireChange(testConfig{Foo: "recreated"})
+}
+
+func TestEtcdProvider_WatchPinnedRevision(t *testing.T) {
+	fake, client := newFakeEtcd(t)
+	fake.put("foo/bar", []byte(`{"foo": "bar"}`))
+
+	_, err := config.Watch[testConfig](context.Background(), config.FromEtcd("foo/bar",
+		config.EtcdWithClient(client),
+		config.EtcdWithRevision(2),
+	))
+	require.Error(t, err)
+}
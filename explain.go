@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// DefaultSource is the source of values set from `default` tag in provenance reports.
+const DefaultSource = "default"
+
+// SourceNamer is implemented by providers that have a human-readable name,
+// e.g. "file:config.json". The name is used in provenance reports.
+type SourceNamer interface {
+	Source() string
+}
+
+// sourceName returns the name of provider or empty string if it's unknown.
+func sourceName(provider ConfigProvider) string {
+	if namer, ok := provider.(SourceNamer); ok {
+		return namer.Source()
+	}
+
+	return ""
+}
+
+// Provenance describes which sources supplied fields of a config.
+// It's ordered like fields of the config and can be encoded to JSON.
+type Provenance []FieldSource
+
+// FieldSource describes the source of a leaf field of a config.
+type FieldSource struct {
+	// Path to the field, e.g. "db.pool.max". Path consists of names from `json` tag
+	// or field names if the tag is absent.
+	Path string `json:"path"`
+	// Value of the field in the config.
+	Value any `json:"value"`
+	// Source that set the value: name of the layer, name of the provider (see SourceNamer),
+	// "pos N" if the provider has no name, or DefaultSource. Empty if no source set the value.
+	Source string `json:"source,omitempty"`
+	// Pos is the position of the configurator that set the value, -1 for DefaultSource
+	// and for fields that weren't set.
+	Pos int `json:"pos"`
+	// Shadowed are values of other sources that set the field in order of merging.
+	// For fields merged from several sources (e.g. maps or `merge:"append"`) they are
+	// parts of the value.
+	Shadowed []ShadowedValue `json:"shadowed,omitempty"`
+}
+
+// ShadowedValue is a value of the field set by a source that didn't win.
+type ShadowedValue struct {
+	Source string `json:"source"`
+	Pos    int    `json:"pos"`
+	Value  any    `json:"value"`
+}
+
+// Field returns the source of the field by path.
+func (p Provenance) Field(path string) (FieldSource, bool) {
+	for _, field := range p {
+		if field.Path == path {
+			return field, true
+		}
+	}
+
+	return FieldSource{}, false
+}
+
+// WriteTable writes provenance to w as a table with columns PATH, VALUE, SOURCE and SHADOWED.
+func (p Provenance) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tVALUE\tSOURCE\tSHADOWED")
+
+	for _, field := range p {
+		shadowed := make([]string, 0, len(field.Shadowed))
+		for _, s := range field.Shadowed {
+			shadowed = append(shadowed, fmt.Sprintf("%s=%s", s.Source, formatValue(s.Value)))
+		}
+
+		source := field.Source
+		if source == "" {
+			source = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", field.Path, formatValue(field.Value), source, strings.Join(shadowed, ", "))
+	}
+
+	return tw.Flush()
+}
+
+// String returns provenance as a table.
+func (p Provenance) String() string {
+	var b strings.Builder
+	_ = p.WriteTable(&b)
+	return b.String()
+}
+
+func formatValue(v any) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("%v", rv.Interface())
+}
+
+// buildTrace holds sources of a config built by multiConfigurator.
+type buildTrace[T any] struct {
+	defaults T
+	sources  []traceSource[T]
+	cfg      T
+}
+
+// traceSource is a config decoded from a source.
+type traceSource[T any] struct {
+	name    string
+	pos     int
+	cfg     T
+	present presence
+}
+
+func (tr *buildTrace[T]) add(l layer[T], cfg T, present presence) {
+	tr.sources = append(tr.sources, traceSource[T]{
+		name:    l.sourceName(),
+		pos:     l.pos,
+		cfg:     cfg,
+		present: present,
+	})
+}
+
+// leafValue is a leaf field of a config.
+type leafValue struct {
+	path     string
+	strategy string
+	secret   bool
+	value    reflect.Value
+}
+
+// shown returns the value of the leaf for provenance reports. Values of fields marked with
+// `secret` tag are replaced with Redacted.
+func (l leafValue) shown() any {
+	if l.secret {
+		return Redacted
+	}
+
+	return l.value.Interface()
+}
+
+func (tr *buildTrace[T]) provenance() Provenance {
+	defaults := collectLeaves(&tr.defaults)
+	sources := make([]map[string]leafValue, 0, len(tr.sources))
+	for i := range tr.sources {
+		sources = append(sources, collectLeaves(&tr.sources[i].cfg))
+	}
+
+	var prov Provenance
+	walkLeaves(reflect.ValueOf(&tr.cfg).Elem(), "", "", "", false, func(goPath string, leaf leafValue) {
+		var setters []ShadowedValue
+		if def, ok := defaults[goPath]; ok && !def.value.IsZero() {
+			setters = append(setters, ShadowedValue{Source: DefaultSource, Pos: -1, Value: def.shown()})
+		}
+
+		for i, src := range tr.sources {
+			if value, ok := sources[i][goPath]; ok && src.present.isSet(value.value, goPath) {
+				setters = append(setters, ShadowedValue{Source: src.name, Pos: src.pos, Value: value.shown()})
+			}
+		}
+
+		field := FieldSource{Path: leaf.path, Value: leaf.shown(), Pos: -1}
+		if len(setters) > 0 {
+			winner := len(setters) - 1
+			if leaf.strategy == mergeKeep {
+				winner = 0
+			}
+
+			field.Source = setters[winner].Source
+			field.Pos = setters[winner].Pos
+			for i, setter := range setters {
+				if i != winner {
+					field.Shadowed = append(field.Shadowed, setter)
+				}
+			}
+		}
+
+		prov = append(prov, field)
+	})
+
+	return prov
+}
+
+// collectLeaves returns leaf fields of config pointed by cfg by Go paths.
+func collectLeaves(cfg any) map[string]leafValue {
+	leaves := map[string]leafValue{}
+	walkLeaves(reflect.ValueOf(cfg).Elem(), "", "", "", false, func(goPath string, leaf leafValue) {
+		leaves[goPath] = leaf
+	})
+
+	return leaves
+}
+
+// walkLeaves calls fn for leaf fields of v. Structs and non-nil pointers to structs are walked
+// recursively, other values are leaves. Go paths are built from field names like presence,
+// paths are built from names in `json` tag. Leaves of fields marked with `secret` tag are secret.
+func walkLeaves(v reflect.Value, goPath, path, strategy string, secret bool, fn func(goPath string, leaf leafValue)) {
+	switch {
+	case v.Kind() == reflect.Struct && hasExportedFields(v.Type()):
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct && hasExportedFields(field.Type)) {
+				continue
+			}
+
+			fieldPath := path
+			if name := fieldName(field); name != "" {
+				fieldPath = joinPath(path, name)
+			}
+
+			walkLeaves(v.Field(i), joinPath(goPath, field.Name), fieldPath, field.Tag.Get(mergeTag), secret || isSecretField(field), fn)
+		}
+	case v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Struct && hasExportedFields(v.Elem().Type()):
+		walkLeaves(v.Elem(), goPath, path, strategy, secret, fn)
+	default:
+		fn(goPath, leafValue{path: path, strategy: strategy, secret: secret, value: v})
+	}
+}
@@ -0,0 +1,108 @@
+package config_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testExplainConfig struct {
+	Host    string         `json:"host" default:"localhost"`
+	Port    int            `json:"port" default:"8080"`
+	Debug   bool           `json:"debug"`
+	Owner   string         `json:"owner" merge:"keep"`
+	DB      *testExplainDB `json:"db"`
+	Ignored string         `json:"-"`
+}
+
+type testExplainDB struct {
+	Pool struct {
+		Max int `json:"max"`
+	} `json:"pool"`
+}
+
+func TestExplain(t *testing.T) {
+	filePath := writeFile(t, "config.json", `{"port": 9090, "owner": "file", "db": {"pool": {"max": 10}}}`)
+
+	multi := config.Multi[testExplainConfig]().
+		Add(config.FromFile(filePath)).
+		Add(config.FromReader(strings.NewReader(`{"port": 0, "owner": "reader", "db": {"pool": {"max": 20}}}`)))
+
+	_, err := multi.Explain()
+	require.Error(t, err)
+
+	cfg, err := multi.AllOf()
+	require.NoError(t, err)
+	require.Equal(t, 0, cfg.Port)
+
+	prov, err := multi.Explain()
+	require.NoError(t, err)
+
+	fileSource := "file:" + filePath
+	require.Equal(t, config.Provenance{
+		{Path: "host", Value: "localhost", Source: config.DefaultSource, Pos: -1},
+		{
+			Path: "port", Value: 0, Source: "pos 1", Pos: 1,
+			Shadowed: []config.ShadowedValue{
+				{Source: config.DefaultSource, Pos: -1, Value: 8080},
+				{Source: fileSource, Pos: 0, Value: 9090},
+			},
+		},
+		{Path: "debug", Value: false, Pos: -1},
+		{
+			Path: "owner", Value: "file", Source: fileSource, Pos: 0,
+			Shadowed: []config.ShadowedValue{{Source: "pos 1", Pos: 1, Value: "reader"}},
+		},
+		{
+			Path: "db.pool.max", Value: 20, Source: "pos 1", Pos: 1,
+			Shadowed: []config.ShadowedValue{{Source: fileSource, Pos: 0, Value: 10}},
+		},
+		{Path: "Ignored", Value: "", Pos: -1},
+	}, prov)
+
+	table := prov.String()
+	require.Contains(t, table, "PATH")
+	require.Regexp(t, `port\s+0\s+pos 1\s+default=8080, `+fileSource+`=9090`, table)
+	require.Regexp(t, `debug\s+false\s+-`, table)
+
+	data, err := json.Marshal(prov)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `{"path":"host","value":"localhost","source":"default","pos":-1}`)
+}
+
+func TestExplain_Layered(t *testing.T) {
+	multi := config.Multi[testExplainConfig]().
+		AddLayer("env", 20, config.FromReader(strings.NewReader(`{"host": "env.local"}`))).
+		AddLayer("file", 10, config.FromReader(strings.NewReader(`{"host": "file.local", "debug": true}`)))
+
+	_, err := multi.Layered()
+	require.NoError(t, err)
+
+	prov, err := multi.Explain()
+	require.NoError(t, err)
+
+	host, ok := prov.Field("host")
+	require.True(t, ok)
+	require.Equal(t, "env", host.Source)
+	require.Equal(t, 0, host.Pos)
+	require.Equal(t, []config.ShadowedValue{
+		{Source: config.DefaultSource, Pos: -1, Value: "localhost"},
+		{Source: "file", Pos: 1, Value: "file.local"},
+	}, host.Shadowed)
+
+	debug, ok := prov.Field("debug")
+	require.True(t, ok)
+	require.Equal(t, "file", debug.Source)
+
+	db, ok := prov.Field("db")
+	require.True(t, ok, "nil pointer to struct is a leaf")
+	require.Nil(t, db.Value.(*testExplainDB))
+
+	_, err = config.Multi[testExplainConfig]().
+		Add(config.FromReader(strings.NewReader(`{`))).
+		AllOf()
+	require.Error(t, err)
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"maps"
+	"testing"
+)
+
+// RestoreDecoders restores decoders registered by RegisterDecoder when the test finishes.
+func RestoreDecoders(t testing.TB) {
+	decoderRegistry.Lock()
+	saved := maps.Clone(decoderRegistry.decoders)
+	decoderRegistry.Unlock()
+
+	t.Cleanup(func() {
+		decoderRegistry.Lock()
+		defer decoderRegistry.Unlock()
+
+		decoderRegistry.decoders = saved
+	})
+}
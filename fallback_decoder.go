@@ -12,11 +12,12 @@ var _ Decoder = &fallbackDecoder{}
 type fallbackDecoder struct {
 	decCtrs []func(io.Reader) Decoder
 	reader  io.Reader
+	strict  bool
 }
 
 // Creates new fallback line of decoders.
 // Call for Decode() returns first successfull result of Decode() call of internal decoder.
-// If all internal decoders fail, return resulting error.
+// If all internal decoders fail, return resulting error, which joins DecodeError of each of them.
 func FallbackDecoder(decCtrs ...func(io.Reader) Decoder) func(r io.Reader) *fallbackDecoder {
 	return func(r io.Reader) *fallbackDecoder {
 		return &fallbackDecoder{
@@ -26,6 +27,11 @@ func FallbackDecoder(decCtrs ...func(io.Reader) Decoder) func(r io.Reader) *fall
 	}
 }
 
+// DisallowUnknownFields makes internal decoders that support strict mode reject unknown fields.
+func (dec *fallbackDecoder) DisallowUnknownFields() {
+	dec.strict = true
+}
+
 func (dec *fallbackDecoder) Decode(v any) error {
 	data, err := io.ReadAll(dec.reader)
 	if err != nil {
@@ -40,9 +46,12 @@ func (dec *fallbackDecoder) Decode(v any) error {
 		}
 
 		d := dCtr(bytes.NewReader(data))
+		if strictDec, ok := d.(strictDecoder); ok && dec.strict {
+			strictDec.DisallowUnknownFields()
+		}
 
 		if err := d.Decode(v); err != nil {
-			resErr = errors.Join(resErr, fmt.Errorf("decoding on pos %d: %w", idx, err))
+			resErr = errors.Join(resErr, fmt.Errorf("decoding on pos %d: %w", idx, newDecodeError("", data, err)))
 			continue
 		}
 
@@ -1,12 +1,18 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"slices"
 )
 
-var _ ConfigProvider = &fallbackProvider{}
+var (
+	_ ConfigProvider        = &fallbackProvider{}
+	_ ContextConfigProvider = &fallbackProvider{}
+)
 
 type fallbackProvider struct {
 	providers []ConfigProvider
@@ -14,7 +20,8 @@ type fallbackProvider struct {
 
 // Creates new fallback line of providers.
 // Call for ProvideConfig() returns first successfull result of ProvideConfig() call of internal provider.
-// If all internal providers fail, return resulting error.
+// If all internal providers fail, return resulting error, which joins ProviderError of each of them.
+// The error matches ErrSourceNotFound only if sources of all internal providers don't exist.
 func FallbackProvider(prs ...ConfigProvider) *fallbackProvider {
 	return &fallbackProvider{
 		providers: prs,
@@ -22,21 +29,72 @@ func FallbackProvider(prs ...ConfigProvider) *fallbackProvider {
 }
 
 func (p *fallbackProvider) ProvideConfig() (io.Reader, error) {
-	var resErr error
+	return p.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is propagated to internal
+// providers like in NewContext. Providers aren't tried after ctx is done.
+func (p *fallbackProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	var (
+		errs     []error
+		notFound []bool
+	)
 	for idx, pr := range p.providers {
 		if pr == nil {
-			resErr = errors.Join(resErr, fmt.Errorf("nil provider on pos %d", idx))
+			errs = append(errs, fmt.Errorf("nil provider on pos %d", idx))
+			notFound = append(notFound, false)
 			continue
 		}
 
-		r, err := pr.ProvideConfig()
+		r, err := provideConfig(ctx, pr)
 		if err != nil {
-			resErr = errors.Join(resErr, fmt.Errorf("provider %d: %w", idx, err))
+			errs = append(errs, fmt.Errorf("provider %d: %w", idx, newProviderError(pr, err)))
+			notFound = append(notFound, isSourceNotFound(err))
+
+			if ctx.Err() != nil {
+				break
+			}
 			continue
 		}
 
 		return r, nil
 	}
 
-	return nil, resErr
+	// Missing sources are hidden if other providers failed for another reason,
+	// so the resulting error doesn't look like a missing source.
+	if slices.Contains(notFound, false) {
+		for i, err := range errs {
+			if notFound[i] {
+				errs[i] = &hiddenNotFoundError{err: err}
+			}
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// hiddenNotFoundError wraps the error of a missing source, so it doesn't match ErrSourceNotFound
+// and os.ErrNotExist, but errors.Is and errors.As still find other errors of its chain,
+// e.g. ProviderError. It has no Unwrap, since errors.Is would find the missing source through it.
+type hiddenNotFoundError struct {
+	err error
+}
+
+// Error implements error.
+func (e *hiddenNotFoundError) Error() string {
+	return e.err.Error()
+}
+
+// Is reports whether an error of the chain matches target, except for missing sources.
+func (e *hiddenNotFoundError) Is(target error) bool {
+	if target == ErrSourceNotFound || target == fs.ErrNotExist {
+		return false
+	}
+
+	return errors.Is(e.err, target)
+}
+
+// As finds the first error of the chain that matches target.
+func (e *hiddenNotFoundError) As(target any) bool {
+	return errors.As(e.err, target)
 }
@@ -1,30 +1,266 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
-var _ ConfigProvider = &fileProvider{}
+// DefaultFileDebounce is the default time window in which file events are coalesced into
+// a single change notification.
+const DefaultFileDebounce = 100 * time.Millisecond
+
+var (
+	_ ConfigProvider        = &fileProvider{}
+	_ ContextConfigProvider = &fileProvider{}
+	_ WatchableProvider     = &fileProvider{}
+	_ FormatHinter          = &fileProvider{}
+	_ SourceNamer           = &fileProvider{}
+)
+
+type FileOption func(*fileOpts) error
+
+type fileOpts struct {
+	debounce     time.Duration
+	pollInterval time.Duration
+}
 
 type fileProvider struct {
-	cfgPath string
+	cfgPath  string
+	funcOpts []FileOption
 }
 
 // ProvideConfig implements ConfigProvider.
 func (f *fileProvider) ProvideConfig() (r io.Reader, err error) {
+	return f.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Reading of the file fails
+// when ctx is done.
+func (f *fileProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
 	file, err := os.Open(f.cfgPath)
 	if err != nil {
 		return nil, fmt.Errorf("open file: %w", err)
 	}
 
-	return file, nil
+	return withContext(ctx, file), nil
+}
+
+// Source implements SourceNamer.
+func (f *fileProvider) Source() string {
+	return "file:" + f.cfgPath
+}
+
+// FormatHint implements FormatHinter. It returns the file extension.
+func (f *fileProvider) FormatHint() string {
+	return strings.ToLower(filepath.Ext(f.cfgPath))
+}
+
+// Watch implements WatchableProvider.
+//
+// It notifies when the file is written, replaced (including symlink swaps, e.g. Kubernetes
+// ConfigMap updates) or recreated after deletion. Deletion itself isn't notified, the last
+// provided config stays actual until the file appears again.
+func (f *fileProvider) Watch(ctx context.Context) (<-chan struct{}, error) {
+	fileOpts := fileOpts{
+		debounce: DefaultFileDebounce,
+	}
+	for _, option := range f.funcOpts {
+		if option == nil {
+			continue
+		}
+
+		if err := option(&fileOpts); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	state := statFile(f.cfgPath)
+	notify := make(chan struct{}, 1)
+
+	if fileOpts.pollInterval > 0 {
+		go f.poll(ctx, fileOpts.pollInterval, state, notify)
+		return notify, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create fs watcher: %w", err)
+	}
+
+	// Directories are watched instead of the file, because the file may be replaced or
+	// recreated, and then the watch on it is lost.
+	dirs := map[string]struct{}{}
+	watchDirs := func(state fileState) error {
+		for _, dir := range state.dirs(f.cfgPath) {
+			if _, ok := dirs[dir]; ok {
+				continue
+			}
+
+			if err := watcher.Add(dir); err != nil {
+				return fmt.Errorf("watch dir '%s': %w", dir, err)
+			}
+			dirs[dir] = struct{}{}
+		}
+
+		return nil
+	}
+
+	if err := watchDirs(state); err != nil {
+		return nil, errors.Join(err, watcher.Close())
+	}
+
+	go func() {
+		defer close(notify)
+		defer watcher.Close()
+
+		debounce := time.NewTimer(fileOpts.debounce)
+		debounce.Stop()
+		defer debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				debounce.Reset(fileOpts.debounce)
+			case <-debounce.C:
+				newState := statFile(f.cfgPath)
+				// Directory of a symlink target may appear only after the swap.
+				_ = watchDirs(newState)
+
+				if newState.changedFrom(state) {
+					trySend(notify)
+				}
+				state = newState
+			}
+		}
+	}()
+
+	return notify, nil
+}
+
+func (f *fileProvider) poll(ctx context.Context, interval time.Duration, state fileState, notify chan struct{}) {
+	defer close(notify)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			newState := statFile(f.cfgPath)
+			if newState.changedFrom(state) {
+				trySend(notify)
+			}
+			state = newState
+		}
+	}
+}
+
+// FileWithDebounce defines the time window in which file events are coalesced into
+// a single change notification. By default, DefaultFileDebounce is used.
+func FileWithDebounce(d time.Duration) FileOption {
+	return func(v *fileOpts) error {
+		if d <= 0 {
+			return fmt.Errorf("debounce must be positive, got %s", d)
+		}
+
+		v.debounce = d
+		return nil
+	}
+}
+
+// FileWithPolling makes Watch poll the file state with interval d instead of using
+// filesystem notifications. Use it for filesystems that don't support notifications (e.g. NFS).
+func FileWithPolling(d time.Duration) FileOption {
+	return func(v *fileOpts) error {
+		if d <= 0 {
+			return fmt.Errorf("polling interval must be positive, got %s", d)
+		}
+
+		v.pollInterval = d
+		return nil
+	}
 }
 
 // FromFile creates a new config provider from a config file.
-func FromFile(cfgPath string) *fileProvider {
+//
+// The provider implements WatchableProvider, so it can be used with Watch to reload config
+// when the file changes.
+func FromFile(cfgPath string, opts ...FileOption) *fileProvider {
 	return &fileProvider{
-		cfgPath: cfgPath,
+		cfgPath:  cfgPath,
+		funcOpts: opts,
+	}
+}
+
+// fileState is a snapshot of the file that the config path resolves to.
+type fileState struct {
+	info     os.FileInfo
+	realPath string
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fileState{}
+	}
+
+	return fileState{info: info, realPath: realPath}
+}
+
+// changedFrom reports whether the file exists and differs from the previous state.
+func (s fileState) changedFrom(prev fileState) bool {
+	if s.info == nil {
+		return false
+	}
+
+	if prev.info == nil {
+		return true
+	}
+
+	return s.realPath != prev.realPath ||
+		!os.SameFile(s.info, prev.info) ||
+		!s.info.ModTime().Equal(prev.info.ModTime()) ||
+		s.info.Size() != prev.info.Size()
+}
+
+// dirs returns directories that must be watched to catch changes of the file.
+func (s fileState) dirs(path string) []string {
+	dirs := []string{filepath.Dir(path)}
+	if s.realPath != "" && filepath.Dir(s.realPath) != dirs[0] {
+		dirs = append(dirs, filepath.Dir(s.realPath))
+	}
+
+	return dirs
+}
+
+// trySend sends notification to ch if it doesn't already have a pending one.
+func trySend(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
 	}
 }
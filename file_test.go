@@ -1,9 +1,12 @@
 package config_test
 
 import (
+	"context"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/MordaTeam/go-config"
 	"github.com/stretchr/testify/require"
@@ -26,3 +29,130 @@ func TestFile(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "bar", cfg.Foo)
 }
+
+func TestFile_Watch(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Opts []config.FileOption
+	}{
+		{
+			Name: "Notify",
+			Opts: []config.FileOption{config.FileWithDebounce(10 * time.Millisecond)},
+		},
+		{
+			Name: "Polling",
+			Opts: []config.FileOption{config.FileWithPolling(10 * time.Millisecond)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			r := require.New(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			dir := t.TempDir()
+			filePath := path.Join(dir, "config.json")
+			r.NoError(os.WriteFile(filePath, []byte(`{"foo": "bar"}`), 0o600))
+
+			notify, err := config.FromFile(filePath, testCase.Opts...).Watch(ctx)
+			r.NoError(err)
+
+			// Write.
+			r.NoError(os.WriteFile(filePath, []byte(`{"foo": "buz"}`), 0o600))
+			requireNotified(t, notify)
+
+			// Atomic replace.
+			tmpPath := path.Join(dir, "config.json.tmp")
+			r.NoError(os.WriteFile(tmpPath, []byte(`{"foo": "replaced"}`), 0o600))
+			r.NoError(os.Rename(tmpPath, filePath))
+			requireNotified(t, notify)
+
+			// Delete and recreate.
+			r.NoError(os.Remove(filePath))
+			requireNotNotified(t, notify)
+			r.NoError(os.WriteFile(filePath, []byte(`{"foo": "recreated"}`), 0o600))
+			requireNotified(t, notify)
+
+			cancel()
+			requireClosed(t, notify)
+		})
+	}
+}
+
+func TestFile_WatchConfigMap(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Layout of mounted Kubernetes ConfigMap:
+	//	config.json -> ..data/config.json
+	//	..data -> ..2025_01_01
+	//	..2025_01_01/config.json
+	dir := t.TempDir()
+	writeVersion := func(version, data string) {
+		r.NoError(os.Mkdir(filepath.Join(dir, version), 0o700))
+		r.NoError(os.WriteFile(filepath.Join(dir, version, "config.json"), []byte(data), 0o600))
+		r.NoError(os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		r.NoError(os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+
+	writeVersion("..2025_01_01", `{"foo": "bar"}`)
+	filePath := filepath.Join(dir, "config.json")
+	r.NoError(os.Symlink(filepath.Join("..data", "config.json"), filePath))
+
+	provider := config.FromFile(filePath, config.FileWithDebounce(10*time.Millisecond))
+	w, err := config.Watch[testConfig](ctx, provider)
+	r.NoError(err)
+	r.Equal(testConfig{Foo: "bar"}, w.Config())
+
+	writeVersion("..2025_01_02", `{"foo": "buz"}`)
+	r.NoError(os.RemoveAll(filepath.Join(dir, "..2025_01_01")))
+
+	select {
+	case change := <-w.Changes():
+		r.Equal(testConfig{Foo: "buz"}, change.New)
+	case <-time.After(time.Second):
+		r.FailNow("no change")
+	}
+}
+
+func TestFile_WatchInvalidOptions(t *testing.T) {
+	_, err := config.FromFile("config.json", config.FileWithDebounce(0)).Watch(context.Background())
+	require.Error(t, err)
+
+	_, err = config.FromFile("config.json", config.FileWithPolling(-time.Second)).Watch(context.Background())
+	require.Error(t, err)
+}
+
+func requireNotified(t testing.TB, notify <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case _, ok := <-notify:
+		require.True(t, ok, "notify channel is closed")
+	case <-time.After(time.Second):
+		require.FailNow(t, "no notification")
+	}
+}
+
+func requireNotNotified(t testing.TB, notify <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-notify:
+		require.FailNow(t, "unexpected notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func requireClosed(t testing.TB, notify <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case _, ok := <-notify:
+		require.False(t, ok, "notify channel isn't closed")
+	case <-time.After(time.Second):
+		require.FailNow(t, "notify channel isn't closed")
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/MordaTeam/go-toolbox/options"
+)
+
+// FormatHinter is implemented by providers that know the format of the provided data,
+// e.g. from the file extension. Readers returned by providers can implement it too to hint
+// the format of their data (e.g. by Content-Type of a response), their hints take precedence.
+type FormatHinter interface {
+	// FormatHint returns the format as file extension with leading dot, e.g. ".json".
+	// Empty string means that the format is unknown. It's called after ProvideConfig,
+	// so it can return the format of the provided data.
+	FormatHint() string
+}
+
+var decoderRegistry = struct {
+	sync.RWMutex
+	decoders map[string]func(io.Reader) Decoder
+}{
+	decoders: map[string]func(io.Reader) Decoder{
+		".json": DecoderWrap(JsonDecoder),
+		".ini":  DecoderWrap(IniDecoder),
+		".yaml": DecoderWrap(YamlDecoder),
+		".yml":  DecoderWrap(YamlDecoder),
+		".toml": DecoderWrap(TomlDecoder),
+		".env":  DecoderWrap(DotenvDecoder),
+	},
+}
+
+// RegisterDecoder registers decoder for the file extension ext (e.g. ".xml") used by WithAutoDecoder.
+// It overrides the decoder that was registered for ext before.
+//
+// Decoders registered by default:
+//
+//	.json        JsonDecoder
+//	.ini         IniDecoder
+//	.yaml, .yml  YamlDecoder
+//	.toml        TomlDecoder
+//	.env         DotenvDecoder
+func RegisterDecoder[D Decoder](ext string, newDec func(r io.Reader) D) {
+	decoderRegistry.Lock()
+	defer decoderRegistry.Unlock()
+
+	decoderRegistry.decoders[normalizeExt(ext)] = DecoderWrap(newDec)
+}
+
+func lookupDecoder(ext string) (func(io.Reader) Decoder, bool) {
+	decoderRegistry.RLock()
+	defer decoderRegistry.RUnlock()
+
+	newDec, ok := decoderRegistry.decoders[normalizeExt(ext)]
+	return newDec, ok
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	return ext
+}
+
+// WithAutoDecoder is an option that chooses the decoder by the format of provided data,
+// e.g. by file extension of FromFile. Use RegisterDecoder to support more formats.
+//
+// If provider doesn't implement FormatHinter or the format is unknown, the decoder
+// set by WithDecoder (or the default one) is used. If there is no decoder registered
+// for the format, an error is returned.
+func WithAutoDecoder() options.Option[cfgOpts] {
+	return func(v *cfgOpts) error {
+		v.autoDecoder = true
+		return nil
+	}
+}
+
+func (o cfgOpts) decoderFor(provider ConfigProvider, r io.Reader) (func(io.Reader) Decoder, error) {
+	if !o.autoDecoder {
+		return o.newDec, nil
+	}
+
+	format := formatHint(r)
+	if format == "" {
+		format = formatHint(provider)
+	}
+	if format == "" {
+		return o.newDec, nil
+	}
+
+	newDec, ok := lookupDecoder(format)
+	if !ok {
+		return nil, fmt.Errorf("no decoder registered for format '%s'", format)
+	}
+
+	return newDec, nil
+}
+
+// formatHint returns the hint of v if it implements FormatHinter.
+func formatHint(v any) string {
+	if hinter, ok := v.(FormatHinter); ok {
+		return hinter.FormatHint()
+	}
+
+	return ""
+}
@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testFormatConfig struct {
+	Foo string `json:"foo" ini:"foo" env:"FOO" xml:"foo"`
+}
+
+func writeFile(t testing.TB, name, data string) string {
+	filePath := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(filePath, []byte(data), 0o600))
+
+	return filePath
+}
+
+func TestAutoDecoder(t *testing.T) {
+	testCases := []struct {
+		Name string
+		File string
+		Data string
+	}{
+		{Name: "JSON", File: "config.json", Data: `{"foo": "bar"}`},
+		{Name: "INI", File: "config.ini", Data: "foo = bar\n"},
+		{Name: "YAML", File: "config.yaml", Data: "foo: bar\n"},
+		{Name: "YML", File: "config.YML", Data: "foo: bar\n"},
+		{Name: "TOML", File: "config.toml", Data: "foo = \"bar\"\n"},
+		{Name: "Env", File: ".env", Data: "FOO=bar\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			filePath := writeFile(t, testCase.File, testCase.Data)
+
+			cfg, err := config.New[testFormatConfig](config.FromFile(filePath), config.WithAutoDecoder())
+			require.NoError(t, err)
+			require.Equal(t, testFormatConfig{Foo: "bar"}, cfg)
+		})
+	}
+}
+
+func TestAutoDecoder_Register(t *testing.T) {
+	filePath := writeFile(t, "config.xml", "<config><foo>bar</foo></config>")
+
+	_, err := config.New[testFormatConfig](config.FromFile(filePath), config.WithAutoDecoder())
+	require.ErrorContains(t, err, "no decoder registered for format '.xml'")
+
+	config.RestoreDecoders(t)
+	config.RegisterDecoder("xml", xml.NewDecoder)
+
+	cfg, err := config.New[testFormatConfig](config.FromFile(filePath), config.WithAutoDecoder())
+	require.NoError(t, err)
+	require.Equal(t, testFormatConfig{Foo: "bar"}, cfg)
+}
+
+func TestAutoDecoder_Multi(t *testing.T) {
+	iniPath := writeFile(t, "config.ini", "foo = ini\n")
+	yamlPath := writeFile(t, "config.yaml", "foo: yaml\n")
+
+	cfg, err := config.Multi[testFormatConfig]().
+		Add(config.FromFile(yamlPath), config.WithAutoDecoder()).
+		Add(config.FromFile(iniPath), config.WithAutoDecoder()).
+		AllOf()
+	require.NoError(t, err)
+	require.Equal(t, testFormatConfig{Foo: "ini"}, cfg)
+}
+
+func TestAutoDecoder_NoHint(t *testing.T) {
+	// Providers without format hint use the configured decoder.
+	cfg, err := config.New[testFormatConfig](
+		config.FromReader(strings.NewReader("<config><foo>bar</foo></config>")),
+		config.WithDecoder(xml.NewDecoder),
+		config.WithAutoDecoder(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, testFormatConfig{Foo: "bar"}, cfg)
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+var (
+	_ ConfigProvider        = &httpProvider{}
+	_ ContextConfigProvider = &httpProvider{}
+	_ SourceNamer           = &httpProvider{}
+	_ FormatHinter          = &httpProvider{}
+	_ FormatHinter          = &httpReader{}
+)
+
+// contentTypeFormats maps media types of responses to formats used by WithAutoDecoder.
+var contentTypeFormats = map[string]string{
+	"application/json":   ".json",
+	"text/json":          ".json",
+	"application/yaml":   ".yaml",
+	"application/x-yaml": ".yaml",
+	"text/yaml":          ".yaml",
+	"text/x-yaml":        ".yaml",
+	"application/toml":   ".toml",
+	"text/x-toml":        ".toml",
+	"text/x-ini":         ".ini",
+}
+
+// HTTPStatusError is returned by FromHTTP provider when the server responds with
+// unexpected status code. Statuses 404 Not Found and 410 Gone are reported with
+// ErrSourceNotFound.
+type HTTPStatusError struct {
+	// URL is the requested URL without credentials and query, like Source of the provider.
+	URL        string
+	StatusCode int
+}
+
+// Error implements error.
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("http get '%s': unexpected status %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+type HTTPOption func(*httpOpts) error
+
+type httpOpts struct {
+	client *http.Client
+	header http.Header
+}
+
+// HTTPWithClient sets the client used for requests. By default, http.DefaultClient is used.
+func HTTPWithClient(client *http.Client) HTTPOption {
+	return func(v *httpOpts) error {
+		if client == nil {
+			return fmt.Errorf("nil http client")
+		}
+
+		v.client = client
+		return nil
+	}
+}
+
+// HTTPWithHeader adds the header to requests.
+func HTTPWithHeader(key, value string) HTTPOption {
+	return func(v *httpOpts) error {
+		v.header.Add(key, value)
+		return nil
+	}
+}
+
+// HTTPWithBearerToken sets the Authorization header of requests to the bearer token.
+func HTTPWithBearerToken(token string) HTTPOption {
+	return func(v *httpOpts) error {
+		v.header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+type httpProvider struct {
+	url      string
+	funcOpts []HTTPOption
+
+	mu sync.Mutex
+	// etag, body and format of the last response for conditional requests.
+	etag   string
+	body   []byte
+	format string
+}
+
+// httpReader is a reader of a response body that knows its format.
+type httpReader struct {
+	*bytes.Reader
+	format string
+}
+
+// FormatHint implements FormatHinter. It returns the format by Content-Type of the response.
+func (r *httpReader) FormatHint() string {
+	return r.format
+}
+
+// FromHTTP returns provider that fetches config by GET request to url.
+//
+// Responses with ETag header are remembered, the next request has If-None-Match header,
+// so the server can answer 304 Not Modified and the remembered body is provided.
+// Statuses other than 200 OK fail with HTTPStatusError or ErrSourceNotFound.
+//
+// The format for WithAutoDecoder is chosen by Content-Type of the response (e.g.
+// application/json or application/yaml), then by extension of the URL path.
+//
+//	cfg, err := config.New[Config](
+//		config.FromHTTP("https://config.internal/service.yaml", config.HTTPWithBearerToken(token)),
+//		config.WithAutoDecoder(),
+//	)
+func FromHTTP(url string, opts ...HTTPOption) *httpProvider {
+	return &httpProvider{
+		url:      url,
+		funcOpts: opts,
+	}
+}
+
+// ProvideConfig implements ConfigProvider.
+func (h *httpProvider) ProvideConfig() (io.Reader, error) {
+	return h.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is passed to the request.
+func (h *httpProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	opts := httpOpts{
+		client: http.DefaultClient,
+		header: http.Header{},
+	}
+	for _, opt := range h.funcOpts {
+		if opt == nil {
+			continue
+		}
+
+		if err := opt(&opts); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	for key, values := range opts.header {
+		req.Header[key] = values
+	}
+
+	h.mu.Lock()
+	etag, cached, cachedFormat := h.etag, h.body, h.format
+	h.mu.Unlock()
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := opts.client.Do(req)
+	if err != nil {
+		// Errors of the client contain the whole URL.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("http get '%s': %w", h.Source(), err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && etag != "":
+		return &httpReader{Reader: bytes.NewReader(cached), format: cachedFormat}, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("http get '%s': status %d: %w", h.Source(), resp.StatusCode, ErrSourceNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, &HTTPStatusError{URL: h.Source(), StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	format := contentTypeFormat(resp.Header.Get("Content-Type"))
+
+	h.mu.Lock()
+	h.etag = resp.Header.Get("ETag")
+	h.body = body
+	h.format = format
+	h.mu.Unlock()
+
+	return &httpReader{Reader: bytes.NewReader(body), format: format}, nil
+}
+
+func contentTypeFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	if format, ok := contentTypeFormats[mediaType]; ok {
+		return format
+	}
+
+	if strings.HasSuffix(mediaType, "+json") {
+		return ".json"
+	}
+	if strings.HasSuffix(mediaType, "+yaml") {
+		return ".yaml"
+	}
+
+	return ""
+}
+
+// Source implements SourceNamer. It returns the URL without credentials and query.
+func (h *httpProvider) Source() string {
+	u, err := url.Parse(h.url)
+	if err != nil {
+		return "http"
+	}
+
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// FormatHint implements FormatHinter. It returns the extension of the URL path. Readers
+// of responses hint the format by Content-Type, which takes precedence.
+func (h *httpProvider) FormatHint() string {
+	u, err := url.Parse(h.url)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(path.Ext(u.Path))
+}
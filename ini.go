@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"io"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/go-ini/ini"
 )
@@ -11,11 +14,33 @@ var _ Decoder = &iniDecoder{}
 
 type iniDecoder struct {
 	reader io.Reader
+	strict bool
+}
+
+// DisallowUnknownFields causes the decoder to return UnknownFieldError when the document
+// contains sections or keys that aren't mapped to any field of the destination.
+func (dec *iniDecoder) DisallowUnknownFields() {
+	dec.strict = true
 }
 
 // Decode implements Decoder.
 func (dec *iniDecoder) Decode(v any) error {
-	if err := ini.MapTo(v, dec.reader); err != nil {
+	file, err := ini.Load(dec.reader)
+	if err != nil {
+		return fmt.Errorf("mapping ini: %w", err)
+	}
+
+	if dec.strict {
+		if err := newUnknownFieldError(unmappedIniPaths(file, reflect.TypeOf(v))); err != nil {
+			return fmt.Errorf("mapping ini: %w", err)
+		}
+	}
+
+	if _, err := mapIniTextFields(file, ini.DefaultSection, reflect.ValueOf(v)); err != nil {
+		return fmt.Errorf("mapping ini: %w", err)
+	}
+
+	if err := file.MapTo(v); err != nil {
 		return fmt.Errorf("mapping ini: %w", err)
 	}
 	return nil
@@ -24,3 +49,193 @@ func (dec *iniDecoder) Decode(v any) error {
 func IniDecoder(r io.Reader) *iniDecoder {
 	return &iniDecoder{reader: r}
 }
+
+// IniStrictDecoder returns IniDecoder that fails on sections and keys that aren't mapped to any field.
+func IniStrictDecoder(r io.Reader) *iniDecoder {
+	return &iniDecoder{reader: r, strict: true}
+}
+
+// unmappedIniPaths returns sections and keys of file that aren't mapped to fields of t.
+// Paths of keys are "section.key", keys of the default section have no prefix.
+func unmappedIniPaths(file *ini.File, t reflect.Type) []string {
+	used := map[string]map[string]bool{}
+	collectIniFields(file, ini.DefaultSection, t, used)
+
+	var paths []string
+	for _, section := range file.Sections() {
+		keys, ok := used[section.Name()]
+		if !ok {
+			paths = append(paths, section.Name())
+			continue
+		}
+
+		for _, key := range section.Keys() {
+			if keys[key.Name()] {
+				continue
+			}
+
+			if section.Name() == ini.DefaultSection {
+				paths = append(paths, key.Name())
+			} else {
+				paths = append(paths, section.Name()+"."+key.Name())
+			}
+		}
+	}
+
+	return paths
+}
+
+// collectIniFields marks section and its keys that are mapped to fields of t like ini.MapTo does.
+func collectIniFields(file *ini.File, section string, t reflect.Type, used map[string]map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if _, ok := used[section]; ok || t.Kind() != reflect.Struct {
+		return
+	}
+	used[section] = map[string]bool{}
+
+	var collect func(t reflect.Type, section string)
+	collect = func(t reflect.Type, section string) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			tag := field.Tag.Get("ini")
+			if tag == "-" || !field.IsExported() {
+				continue
+			}
+
+			rawName, opts, _ := strings.Cut(tag, ",")
+			name := rawName
+			if name == "" {
+				name = field.Name
+			}
+
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+
+			if field.Anonymous && fieldType.Kind() == reflect.Struct && strings.Contains(opts, "extends") {
+				if rawName == "" {
+					collect(fieldType, section)
+				} else {
+					collectIniFields(file, section+"."+rawName, fieldType, used)
+				}
+				continue
+			}
+
+			if fieldType.Kind() == reflect.Struct && file.HasSection(name) {
+				collectIniFields(file, name, fieldType, used)
+				continue
+			}
+
+			if field.Type.Kind() == reflect.Slice && strings.Contains(opts, "nonunique") {
+				collectIniFields(file, name, field.Type.Elem(), used)
+			}
+
+			used[section][name] = true
+		}
+	}
+	collect(t, section)
+}
+
+// mapIniTextFields maps keys of section to fields of struct v that implement
+// encoding.TextUnmarshaler and have types unsupported by ini.MapTo (e.g. Secret).
+// Mapped keys are deleted from the file, so ini.MapTo skips them. It reports whether
+// any key was mapped.
+func mapIniTextFields(file *ini.File, section string, v reflect.Value) (bool, error) {
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return false, nil
+	}
+
+	sec := file.Section(section)
+	mapped := false
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("ini")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		rawName, opts, _ := strings.Cut(tag, ",")
+		name := rawName
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		var (
+			ok  bool
+			err error
+		)
+		switch {
+		case isIniTextType(fieldType):
+			if !sec.HasKey(name) {
+				continue
+			}
+
+			if err := setFromString(v.Field(i), sec.Key(name).String()); err != nil {
+				return mapped, fmt.Errorf("set field %q: %w", name, err)
+			}
+			sec.DeleteKey(name)
+			ok = true
+		case field.Anonymous && fieldType.Kind() == reflect.Struct && strings.Contains(opts, "extends"):
+			if rawName == "" {
+				ok, err = mapIniTextStruct(file, section, v.Field(i))
+			} else {
+				ok, err = mapIniTextStruct(file, section+"."+rawName, v.Field(i))
+			}
+		case fieldType.Kind() == reflect.Struct && file.HasSection(name):
+			ok, err = mapIniTextStruct(file, name, v.Field(i))
+		}
+		if err != nil {
+			return mapped, fmt.Errorf("map to field %q: %w", name, err)
+		}
+		mapped = mapped || ok
+	}
+
+	return mapped, nil
+}
+
+// mapIniTextStruct maps section to struct or pointer to struct v like mapIniTextFields.
+// Nil pointer is allocated only if any key was mapped.
+func mapIniTextStruct(file *ini.File, section string, v reflect.Value) (bool, error) {
+	if v.Kind() != reflect.Pointer || !v.IsNil() {
+		return mapIniTextFields(file, section, v)
+	}
+
+	elem := reflect.New(v.Type().Elem())
+	mapped, err := mapIniTextFields(file, section, elem)
+	if mapped {
+		v.Set(elem)
+	}
+
+	return mapped, err
+}
+
+// isIniTextType reports whether t implements encoding.TextUnmarshaler and isn't supported
+// by ini.MapTo natively.
+func isIniTextType(t reflect.Type) bool {
+	if !reflect.PointerTo(t).Implements(textUnmarshalerType) || t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+var _ Decoder = &jsonDecoder{}
+
+type jsonDecoder struct {
+	reader io.Reader
+	strict bool
+}
+
+// DisallowUnknownFields causes the decoder to return UnknownFieldError when the document
+// contains keys that don't match any field of the destination.
+func (dec *jsonDecoder) DisallowUnknownFields() {
+	dec.strict = true
+}
+
+// Decode implements Decoder.
+func (dec *jsonDecoder) Decode(v any) error {
+	if !dec.strict {
+		return json.NewDecoder(dec.reader).Decode(v)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(dec.reader).Decode(&raw); err != nil {
+		return err
+	}
+
+	var tree any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return err
+	}
+
+	if err := newUnknownFieldError(unknownJSONPaths(tree, reflect.TypeOf(v), "")); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// JsonDecoder returns decoder that decodes JSON like json.Decoder. It's the default decoder.
+// Unlike json.Decoder, in strict mode it reports all unknown keys with UnknownFieldError.
+func JsonDecoder(r io.Reader) *jsonDecoder {
+	return &jsonDecoder{reader: r}
+}
+
+// JsonStrictDecoder returns JsonDecoder that fails on keys that don't match any field.
+func JsonStrictDecoder(r io.Reader) *jsonDecoder {
+	return &jsonDecoder{reader: r, strict: true}
+}
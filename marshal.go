@@ -0,0 +1,773 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/MordaTeam/go-toolbox/options"
+	"github.com/go-ini/ini"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encoders encode configs by formats. Secrets are shown if secrets is true.
+var encoders = map[string]func(w io.Writer, v reflect.Value, secrets bool) error{
+	".json": func(w io.Writer, v reflect.Value, secrets bool) error {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(dumpTree{tag: "json", secrets: secrets}.value(v, false))
+	},
+	".yaml": encodeYaml,
+	".yml":  encodeYaml,
+	".toml": func(w io.Writer, v reflect.Value, secrets bool) error {
+		tree := dumpTree{tag: "toml", secrets: secrets}.value(v, false)
+		obj, ok := tree.(dumpObject)
+		if !ok {
+			return errors.New("config must be a struct or a map")
+		}
+
+		return toml.NewEncoder(w).Encode(obj.tomlTable())
+	},
+	".ini":     encodeIni,
+	".env":     encodeEnv,
+	".cmdline": encodeCmdline,
+}
+
+type dumpOpts struct {
+	format  string
+	secrets bool
+}
+
+func newDumpOpts(opts ...options.Option[dumpOpts]) (dumpOpts, error) {
+	dumpOpts := dumpOpts{
+		format: ".json",
+	}
+
+	if err := options.ApplyOptions(&dumpOpts, opts...); err != nil {
+		return dumpOpts, fmt.Errorf("apply option: %w", err)
+	}
+
+	return dumpOpts, nil
+}
+
+// DumpWithFormat is an option that defines the format of Dump. By default, it's JSON.
+// See Marshal for supported formats.
+func DumpWithFormat(format string) options.Option[dumpOpts] {
+	return func(v *dumpOpts) error {
+		if _, ok := encoders[normalizeExt(format)]; !ok {
+			return fmt.Errorf("unsupported format '%s'", format)
+		}
+
+		v.format = normalizeExt(format)
+		return nil
+	}
+}
+
+// DumpWithSecrets is an option that makes Dump and Marshal show values of secrets, e.g.
+// to reproduce the config locally. Never use it for logs.
+func DumpWithSecrets() options.Option[dumpOpts] {
+	return func(v *dumpOpts) error {
+		v.secrets = true
+		return nil
+	}
+}
+
+// Marshal encodes cfg in format. Values of Secret and fields marked with `secret:"true"` tag
+// are replaced with Redacted unless DumpWithSecrets is passed. Nil pointers are skipped,
+// except in JSON and YAML where they are null.
+//
+// Supported formats (with or without leading dot, like in RegisterDecoder):
+//
+//	json         indented JSON, names from `json` tag
+//	yaml, yml    YAML, names from `yaml` tag, then from `json` tag
+//	toml         TOML, names from `toml` tag, then from `json` tag
+//	ini          INI, names from `ini` tag, nested structs are sections, map fields
+//	             aren't supported and must be excluded with `ini:"-"` tag
+//	env          env file (KEY=value), names from `env` and `envPrefix` tags
+//	cmdline      command line flags in one line, names from `long`, `short` and `namespace`
+//	             tags, arguments with special characters are quoted for POSIX shell
+//
+// Output of each format can be decoded by the decoder of the format (cmdline is meant to be
+// passed through shell), so the effective config built by Multi can be reproduced locally:
+//
+//	data, err := config.Marshal(cfg, "yaml")
+func Marshal(cfg any, format string, opts ...options.Option[dumpOpts]) ([]byte, error) {
+	opts = append(slices.Clip(opts), DumpWithFormat(format))
+
+	var buf bytes.Buffer
+	if err := dump(&buf, cfg, opts...); err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Dump writes cfg to w as indented JSON with secrets redacted. Values of Secret and fields
+// marked with `secret:"true"` tag are shown as Redacted. Use DumpWithFormat to choose
+// another format (see Marshal):
+//
+//	cfg, err := config.Multi[Config]().
+//		Add(config.FromFile("config.json")).
+//		Add(config.FromEnv(), config.WithDecoder(config.EnvDecoder)).
+//		AllOf()
+//	// ...
+//	_ = config.Dump(os.Stderr, cfg, config.DumpWithFormat("yaml"))
+func Dump(w io.Writer, cfg any, opts ...options.Option[dumpOpts]) error {
+	if err := dump(w, cfg, opts...); err != nil {
+		return fmt.Errorf("dump config: %w", err)
+	}
+
+	return nil
+}
+
+func dump(w io.Writer, cfg any, opts ...options.Option[dumpOpts]) error {
+	dumpOpts, err := newDumpOpts(opts...)
+	if err != nil {
+		return err
+	}
+
+	return encoders[dumpOpts.format](w, reflect.ValueOf(cfg), dumpOpts.secrets)
+}
+
+// String returns cfg as one-line JSON with secrets redacted like in Dump.
+// If cfg can't be encoded to JSON, redacted cfg (see Redact) is formatted with %+v.
+func String(cfg any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(dumpTree{tag: "json"}.value(reflect.ValueOf(cfg), false)); err != nil {
+		return fmt.Sprintf("%+v", redactAny(cfg))
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// dumpField is a named value of dumpObject.
+type dumpField struct {
+	name  string
+	value any
+}
+
+// dumpObject is an object of dumped config that keeps the order of fields.
+type dumpObject []dumpField
+
+// MarshalJSON implements json.Marshaler.
+func (o dumpObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		if err := enc.Encode(f.name); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := enc.Encode(f.value); err != nil {
+			return nil, fmt.Errorf("field '%s': %w", f.name, err)
+		}
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (o dumpObject) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range o {
+		var value yaml.Node
+		if err := value.Encode(f.value); err != nil {
+			return nil, fmt.Errorf("field '%s': %w", f.name, err)
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.name}, &value)
+	}
+
+	return node, nil
+}
+
+// tomlTable converts the object to map for TOML encoder, which can't encode nil values.
+func (o dumpObject) tomlTable() map[string]any {
+	table := make(map[string]any, len(o))
+	for _, f := range o {
+		if value := tomlValue(f.value); value != nil {
+			table[f.name] = value
+		}
+	}
+
+	return table
+}
+
+func tomlValue(v any) any {
+	switch v := v.(type) {
+	case dumpObject:
+		return v.tomlTable()
+	case []any:
+		tables := make([]map[string]any, 0, len(v))
+		values := make([]any, 0, len(v))
+		for _, item := range v {
+			item = tomlValue(item)
+			if table, ok := item.(map[string]any); ok {
+				tables = append(tables, table)
+			}
+			if item != nil {
+				values = append(values, item)
+			}
+		}
+
+		if len(tables) > 0 && len(tables) == len(values) {
+			// Array of tables.
+			return tables
+		}
+		return values
+	default:
+		return v
+	}
+}
+
+// dumpTree converts configs into trees of dumpObject, slices and leaf values for
+// structured formats. Fields are named by tag, then by `json` tag, then by field name
+// (lowercased if lowerNames is true) like decoders of the package do.
+type dumpTree struct {
+	tag        string
+	lowerNames bool
+	secrets    bool
+}
+
+func (d dumpTree) value(v reflect.Value, secret bool) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.Type().Implements(secretValueType) {
+		if !d.secrets {
+			return Redacted
+		}
+
+		return d.value(v.Index(0), false)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Struct:
+	default:
+		// Secret slices and maps are redacted as a whole like leaves, secret structs
+		// keep their fields.
+		if secret && !d.secrets {
+			return Redacted
+		}
+	}
+
+	if isDumpLeaf(v.Type()) {
+		if secret && !d.secrets {
+			return Redacted
+		}
+
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return d.value(v.Elem(), secret)
+	case reflect.Struct:
+		var obj dumpObject
+		d.fields(v, secret, &obj)
+		return obj
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		obj := make(dumpObject, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			obj = append(obj, dumpField{name: fmt.Sprint(iter.Key().Interface()), value: d.value(iter.Value(), secret)})
+		}
+		sort.Slice(obj, func(i, j int) bool { return obj[i].name < obj[j].name })
+
+		return obj
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+
+		list := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, d.value(v.Index(i), secret))
+		}
+
+		return list
+	}
+
+	return nil
+}
+
+// fields appends fields of struct v to obj. Embedded structs without name in tag are inlined.
+func (d dumpTree) fields(v reflect.Value, secret bool, obj *dumpObject) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			if isPromoted(field) {
+				d.fields(v.Field(i), secret || isSecretField(field), obj)
+			}
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup(d.tag)
+		if !ok {
+			tag = field.Tag.Get("json")
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldVal := v.Field(i)
+		fieldSecret := secret || isSecretField(field)
+		if name == "" && field.Anonymous {
+			inline := fieldVal
+			if inline.Kind() == reflect.Pointer && !inline.IsNil() {
+				inline = inline.Elem()
+			}
+
+			if inline.Kind() == reflect.Struct && !isDumpLeaf(inline.Type()) {
+				d.fields(inline, fieldSecret, obj)
+				continue
+			}
+		}
+
+		if strings.Contains(opts, "omitempty") && fieldVal.IsZero() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+			if d.lowerNames {
+				name = strings.ToLower(name)
+			}
+		}
+
+		*obj = append(*obj, dumpField{name: name, value: d.value(fieldVal, fieldSecret)})
+	}
+}
+
+// isPromoted reports whether field is an unexported embedded struct, whose exported fields
+// are promoted like in encoding/json.
+func isPromoted(field reflect.StructField) bool {
+	return !field.IsExported() && field.Anonymous && field.Type.Kind() == reflect.Struct
+}
+
+// isDumpLeaf reports whether values of t are dumped as a whole: scalars, types that marshal
+// themselves and structs without exported fields (e.g. time.Time).
+func isDumpLeaf(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return !hasExportedFields(t)
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Array:
+		return false
+	default:
+		return true
+	}
+}
+
+func encodeYaml(w io.Writer, v reflect.Value, secrets bool) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(dumpTree{tag: "yaml", lowerNames: true, secrets: secrets}.value(v, false)); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// flatDumper formats values of flat formats (INI, env and cmdline) as text.
+type flatDumper struct {
+	secrets bool
+}
+
+// text formats leaf value v. Slices and arrays are joined by sep, maps are joined by sep
+// as key{kvSep}value pairs. It returns false if v can't be formatted or it's nil.
+func (f flatDumper) text(v reflect.Value, secret bool, sep, kvSep string) (string, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	if v.Type().Implements(secretValueType) {
+		if !f.secrets {
+			return Redacted, true
+		}
+
+		return f.text(v.Index(0), false, sep, kvSep)
+	}
+
+	if secret && !f.secrets {
+		return Redacted, true
+	}
+
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), true
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err == nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface()), true
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, ok := f.text(v.Index(i), false, sep, kvSep)
+			if !ok {
+				return "", false
+			}
+			items = append(items, item)
+		}
+
+		return strings.Join(items, sep), true
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value, ok := f.text(iter.Value(), false, sep, kvSep)
+			if !ok {
+				return "", false
+			}
+			items = append(items, fmt.Sprint(iter.Key().Interface())+kvSep+value)
+		}
+		sort.Strings(items)
+
+		return strings.Join(items, sep), true
+	default:
+		return "", false
+	}
+}
+
+// isFlatGroup reports whether t is a struct whose fields are dumped separately
+// in flat formats.
+func isFlatGroup(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && !isDumpLeaf(t) && !t.Implements(secretValueType)
+}
+
+// structValue dereferences pointers of v. It returns false if v isn't a struct or it's nil.
+func structValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+
+	return v, v.Kind() == reflect.Struct
+}
+
+func encodeIni(w io.Writer, v reflect.Value, secrets bool) error {
+	v, ok := structValue(v)
+	if !ok {
+		return errors.New("config must be a struct")
+	}
+
+	file := ini.Empty()
+	if err := (flatDumper{secrets: secrets}).iniSection(file, ini.DefaultSection, v, false); err != nil {
+		return err
+	}
+
+	_, err := file.WriteTo(w)
+	return err
+}
+
+// iniSection adds fields of struct v to section like ini.MapTo maps them.
+func (f flatDumper) iniSection(file *ini.File, section string, v reflect.Value, secret bool) error {
+	sec := file.Section(section)
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("ini")
+		if tag == "-" {
+			continue
+		}
+
+		if !field.IsExported() {
+			if isPromoted(field) {
+				if err := f.iniSection(file, section, v.Field(i), secret || isSecretField(field)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		rawName, opts, _ := strings.Cut(tag, ",")
+		name := rawName
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSecret := secret || isSecretField(field)
+		if isFlatGroup(field.Type) {
+			fieldVal, ok := structValue(v.Field(i))
+			if !ok {
+				continue
+			}
+
+			child := name
+			if field.Anonymous && strings.Contains(opts, "extends") {
+				child = section
+				if rawName != "" {
+					child = section + "." + rawName
+				}
+			}
+
+			if err := f.iniSection(file, child, fieldVal, fieldSecret); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Map {
+			// Maps aren't supported by INI decoder, so they can't be decoded back.
+			return fmt.Errorf("field '%s': maps aren't supported by INI, exclude the field with `ini:\"-\"` tag", name)
+		}
+
+		delim := field.Tag.Get("delim")
+		if delim == "" {
+			delim = ","
+		}
+
+		value, ok := f.text(v.Field(i), fieldSecret, delim, ":")
+		if !ok {
+			continue
+		}
+
+		if _, err := sec.NewKey(name, value); err != nil {
+			return fmt.Errorf("field '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func encodeEnv(w io.Writer, v reflect.Value, secrets bool) error {
+	v, ok := structValue(v)
+	if !ok {
+		return errors.New("config must be a struct")
+	}
+
+	var lines []string
+	(flatDumper{secrets: secrets}).envVars(v, "", false, &lines)
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// envVars appends variables of struct v to lines like env library parses them.
+func (f flatDumper) envVars(v reflect.Value, prefix string, secret bool, lines *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			if isPromoted(field) {
+				f.envVars(v.Field(i), prefix+field.Tag.Get("envPrefix"), secret || isSecretField(field), lines)
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+		fieldSecret := secret || isSecretField(field)
+		if name == "" || name == "-" {
+			if fieldVal, ok := structValue(v.Field(i)); ok && isFlatGroup(field.Type) {
+				f.envVars(fieldVal, prefix+field.Tag.Get("envPrefix"), fieldSecret, lines)
+			}
+			continue
+		}
+
+		sep := field.Tag.Get("envSeparator")
+		if sep == "" {
+			sep = ","
+		}
+		value, ok := f.text(v.Field(i), fieldSecret, sep, ":")
+		if !ok {
+			continue
+		}
+
+		*lines = append(*lines, prefix+name+"="+quoteEnvValue(value))
+	}
+}
+
+var plainEnvValue = regexp.MustCompile(`^[^\s"'\\#]*$`)
+
+// quoteEnvValue quotes value for env file if it contains spaces, quotes or other characters
+// that DotenvDecoder treats specially.
+func quoteEnvValue(value string) string {
+	if plainEnvValue.MatchString(value) {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
+
+func encodeCmdline(w io.Writer, v reflect.Value, secrets bool) error {
+	v, ok := structValue(v)
+	if !ok {
+		return errors.New("config must be a struct")
+	}
+
+	var args []string
+	(flatDumper{secrets: secrets}).flags(v, "", false, &args)
+
+	for i, arg := range args {
+		args[i] = quoteShellArg(arg)
+	}
+
+	_, err := io.WriteString(w, strings.Join(args, cmdSep))
+	return err
+}
+
+// flags appends flags of struct v to args like go-flags library parses them.
+func (f flatDumper) flags(v reflect.Value, namespace string, secret bool, args *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if (!field.IsExported() && !isPromoted(field)) || field.Tag.Get("no-flag") != "" ||
+			field.Tag.Get("command") != "" || field.Tag.Get("positional-args") != "" {
+			continue
+		}
+
+		long, short := field.Tag.Get("long"), field.Tag.Get("short")
+		fieldSecret := secret || isSecretField(field)
+		if long == "" && short == "" || !field.IsExported() {
+			if fieldVal, ok := structValue(v.Field(i)); ok && isFlatGroup(field.Type) {
+				ns := namespace
+				// Namespaces are applied to groups only.
+				if n := field.Tag.Get("namespace"); n != "" && field.Tag.Get("group") != "" {
+					ns = joinPath(ns, n)
+				}
+				f.flags(fieldVal, ns, fieldSecret, args)
+			}
+			continue
+		}
+
+		flag := "-" + short
+		if long != "" {
+			flag = "--" + joinPath(namespace, long)
+		}
+
+		f.flagValues(v.Field(i), flag, fieldSecret, args)
+	}
+}
+
+// flagValues appends flag with value v to args. Slices and maps are repeated flags,
+// booleans are flags without values.
+func (f flatDumper) flagValues(v reflect.Value, flag string, secret bool, args *[]string) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	appendFlag := func(value string) {
+		if strings.HasPrefix(flag, "--") {
+			*args = append(*args, flag+"="+value)
+		} else {
+			*args = append(*args, flag, value)
+		}
+	}
+
+	switch {
+	case v.Kind() == reflect.Bool:
+		if v.Bool() {
+			*args = append(*args, flag)
+		}
+	case v.Type().Implements(secretValueType), v.Type().Implements(textMarshalerType):
+		if value, ok := f.text(v, secret, ",", ":"); ok {
+			appendFlag(value)
+		}
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			f.flagValues(v.Index(i), flag, secret, args)
+		}
+	case v.Kind() == reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if value, ok := f.text(iter.Value(), secret, ",", ":"); ok {
+				items = append(items, fmt.Sprint(iter.Key().Interface())+":"+value)
+			}
+		}
+		sort.Strings(items)
+
+		for _, item := range items {
+			appendFlag(item)
+		}
+	default:
+		if value, ok := f.text(v, secret, ",", ":"); ok {
+			appendFlag(value)
+		}
+	}
+}
+
+var plainShellArg = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// quoteShellArg quotes arg for POSIX shell if it contains special characters.
+func quoteShellArg(arg string) string {
+	if plainShellArg.MatchString(arg) {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
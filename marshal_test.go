@@ -0,0 +1,267 @@
+package config_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testMarshalConfig struct {
+	Name     string                `json:"name" yaml:"name" toml:"name" ini:"name" env:"TEST_NAME" long:"name"`
+	Port     int                   `json:"port" yaml:"port" toml:"port" ini:"port" env:"TEST_PORT" long:"port"`
+	Debug    bool                  `json:"debug" yaml:"debug" toml:"debug" ini:"debug" env:"TEST_DEBUG" long:"debug"`
+	Timeout  time.Duration         `json:"timeout" yaml:"timeout" toml:"timeout" ini:"timeout" env:"TEST_TIMEOUT" long:"timeout"`
+	Hosts    []string              `json:"hosts" yaml:"hosts" toml:"hosts" ini:"hosts" env:"TEST_HOSTS" long:"host"`
+	Labels   map[string]string     `json:"labels" yaml:"labels" toml:"labels" ini:"-" env:"TEST_LABELS" long:"label"`
+	Password config.Secret[string] `json:"password" yaml:"password" toml:"password" ini:"password" env:"TEST_PASSWORD" long:"password"`
+	DB       testMarshalDB         `json:"db" yaml:"db" toml:"db" ini:"db" envPrefix:"TEST_DB_" group:"db" namespace:"db"`
+}
+
+type testMarshalDB struct {
+	Host  string `json:"host" yaml:"host" toml:"host" ini:"host" env:"HOST" long:"host"`
+	Token string `json:"token" yaml:"token" toml:"token" ini:"token" env:"TOKEN" long:"token" secret:"true"`
+}
+
+func expMarshalConfig() testMarshalConfig {
+	return testMarshalConfig{
+		Name:     "service",
+		Port:     8080,
+		Debug:    true,
+		Timeout:  5 * time.Second,
+		Hosts:    []string{"db-1", "db-2"},
+		Labels:   map[string]string{"env": "dev", "team": "core"},
+		Password: config.NewSecret("p@ss"),
+		DB:       testMarshalDB{Host: "localhost", Token: "t0ken"},
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		format string
+		dec    func(io.Reader) config.Decoder
+		labels bool
+		shell  bool // redacted values are quoted for shell
+	}{
+		{format: "json", dec: func(r io.Reader) config.Decoder { return config.JsonStrictDecoder(r) }, labels: true},
+		{format: "yaml", dec: func(r io.Reader) config.Decoder { return config.YamlStrictDecoder(r) }, labels: true},
+		{format: ".toml", dec: func(r io.Reader) config.Decoder { return config.TomlStrictDecoder(r) }, labels: true},
+		{format: "ini", dec: func(r io.Reader) config.Decoder { return config.IniStrictDecoder(r) }},
+		{format: "env", dec: func(r io.Reader) config.Decoder { return config.DotenvDecoder(r) }, labels: true},
+		{format: "cmdline", dec: func(r io.Reader) config.Decoder { return config.CmdlineDecoder(r) }, labels: true, shell: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r := require.New(t)
+			expected := expMarshalConfig()
+			if !tt.labels {
+				expected.Labels = nil
+			}
+
+			data, err := config.Marshal(expMarshalConfig(), tt.format)
+			r.NoError(err)
+			r.NotContains(string(data), "p@ss")
+			r.NotContains(string(data), "t0ken")
+
+			if !tt.shell {
+				cfg, err := config.New[testMarshalConfig](config.FromReader(bytes.NewReader(data)), config.WithDecoder(tt.dec))
+				r.NoError(err, string(data))
+				r.Equal(config.Redacted, cfg.Password.Value())
+				r.Equal(config.Redacted, cfg.DB.Token)
+			}
+
+			data, err = config.Marshal(expMarshalConfig(), tt.format, config.DumpWithSecrets())
+			r.NoError(err)
+
+			cfg, err := config.New[testMarshalConfig](config.FromReader(bytes.NewReader(data)), config.WithDecoder(tt.dec))
+			r.NoError(err, string(data))
+			r.Equal(expected, cfg)
+		})
+	}
+}
+
+func TestMarshal_Output(t *testing.T) {
+	cfg := expMarshalConfig()
+
+	data, err := config.Marshal(cfg, "env")
+	require.NoError(t, err)
+	require.Equal(t, `TEST_NAME=service
+TEST_PORT=8080
+TEST_DEBUG=true
+TEST_TIMEOUT=5s
+TEST_HOSTS=db-1,db-2
+TEST_LABELS=env:dev,team:core
+TEST_PASSWORD=***
+TEST_DB_HOST=localhost
+TEST_DB_TOKEN=***
+`, string(data))
+
+	data, err = config.Marshal(cfg, "cmdline")
+	require.NoError(t, err)
+	require.Equal(t, "--name=service --port=8080 --debug --timeout=5s --host=db-1 --host=db-2 "+
+		"--label=env:dev --label=team:core '--password=***' --db.host=localhost '--db.token=***'", string(data))
+
+	data, err = config.Marshal(cfg, "yaml")
+	require.NoError(t, err)
+	require.Equal(t, `name: service
+port: 8080
+debug: true
+timeout: 5s
+hosts:
+  - db-1
+  - db-2
+labels:
+  env: dev
+  team: core
+password: '***'
+db:
+  host: localhost
+  token: '***'
+`, string(data))
+
+	var buf bytes.Buffer
+	require.NoError(t, config.Dump(&buf, cfg, config.DumpWithFormat("ini")))
+	require.Contains(t, buf.String(), "[db]\n")
+	require.Contains(t, buf.String(), "token = ***\n")
+}
+
+func TestMarshal_Values(t *testing.T) {
+	type Inner struct {
+		Value string `json:"value"`
+	}
+	type cfgType struct {
+		Inner
+		Empty   string            `json:"empty,omitempty"`
+		Skipped string            `json:"-"`
+		Ptr     *Inner            `json:"ptr"`
+		Quoted  string            `json:"quoted" env:"TEST_QUOTED" long:"quoted"`
+		Keys    []int             `json:"keys" secret:"true"`
+		Started time.Time         `json:"started"`
+		Extra   map[string]any    `json:"extra"`
+		Tags    map[string]string `json:"tags" env:"TEST_TAGS" envSeparator:";"`
+	}
+
+	cfg := cfgType{
+		Inner:   Inner{Value: "inlined"},
+		Skipped: "skipped",
+		Quoted:  `it's "quoted" & <escaped>`,
+		Keys:    []int{1, 2},
+		Started: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Extra:   map[string]any{"b": 1, "a": []any{"x"}},
+		Tags:    map[string]string{"a": "1", "b": "2"},
+	}
+
+	data, err := config.Marshal(&cfg, "json")
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "value": "inlined",
+  "ptr": null,
+  "quoted": "it's \"quoted\" & <escaped>",
+  "keys": "***",
+  "started": "2025-01-01T00:00:00Z",
+  "extra": {
+    "a": [
+      "x"
+    ],
+    "b": 1
+  },
+  "tags": {
+    "a": "1",
+    "b": "2"
+  }
+}
+`, string(data))
+
+	data, err = config.Marshal(&cfg, "json", config.DumpWithSecrets())
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"keys": [`)
+
+	data, err = config.Marshal(cfg, "env")
+	require.NoError(t, err)
+	require.Equal(t, "TEST_QUOTED=\"it's \\\"quoted\\\" & <escaped>\"\nTEST_TAGS=a:1;b:2\n", string(data))
+
+	parsed, err := config.New[cfgType](config.FromReader(bytes.NewReader(data)), config.WithDecoder(config.DotenvDecoder))
+	require.NoError(t, err)
+	require.Equal(t, cfg.Quoted, parsed.Quoted)
+	require.Equal(t, cfg.Tags, parsed.Tags)
+
+	data, err = config.Marshal(cfg, "cmdline")
+	require.NoError(t, err)
+	require.Equal(t, `'--quoted=it'\''s "quoted" & <escaped>'`, string(data))
+
+	require.Equal(t, strings.TrimSpace(`{"value":"inlined","ptr":null,"quoted":"it's \"quoted\" & <escaped>","keys":"***",`+
+		`"started":"2025-01-01T00:00:00Z","extra":{"a":["x"],"b":1},"tags":{"a":"1","b":"2"}}`), config.String(cfg))
+}
+
+type testMarshalCreds struct {
+	User     string `json:"user" ini:"user" env:"TEST_USER" long:"user"`
+	Password string `json:"password" ini:"password" env:"TEST_PASSWORD" long:"password" secret:"true"`
+}
+
+func TestMarshal_Embedded(t *testing.T) {
+	type cfgType struct {
+		testMarshalCreds
+		Host string `json:"host" ini:"host" env:"TEST_HOST" long:"host"`
+	}
+
+	cfg := cfgType{testMarshalCreds: testMarshalCreds{User: "app", Password: "hunter2"}, Host: "db"}
+
+	expected := map[string]string{
+		"json":    "{\n  \"user\": \"app\",\n  \"password\": \"hunter2\",\n  \"host\": \"db\"\n}\n",
+		"yaml":    "user: app\npassword: hunter2\nhost: db\n",
+		"toml":    "host = \"db\"\npassword = \"hunter2\"\nuser = \"app\"\n",
+		"ini":     "user     = app\npassword = hunter2\nhost     = db\n",
+		"env":     "TEST_USER=app\nTEST_PASSWORD=hunter2\nTEST_HOST=db\n",
+		"cmdline": "--user=app --password=hunter2 --host=db",
+	}
+	for format, exp := range expected {
+		data, err := config.Marshal(cfg, format, config.DumpWithSecrets())
+		require.NoError(t, err, format)
+		require.Equal(t, exp, string(data), format)
+
+		data, err = config.Marshal(cfg, format)
+		require.NoError(t, err, format)
+		require.NotContains(t, string(data), "hunter2", format)
+		require.Contains(t, string(data), "app", format)
+	}
+}
+
+func TestMarshal_Options(t *testing.T) {
+	// Marshal doesn't append to the backing array of passed options.
+	opts := withSpareCap(config.DumpWithSecrets())
+
+	_, err := config.Marshal(expMarshalConfig(), "yaml", opts...)
+	require.NoError(t, err)
+	require.Nil(t, opts[:cap(opts)][1])
+}
+
+// withSpareCap returns slice of v with free capacity.
+func withSpareCap[T any](v T) []T {
+	s := make([]T, 1, 2)
+	s[0] = v
+	return s
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	_, err := config.Marshal(expMarshalConfig(), "xml")
+	require.ErrorContains(t, err, "unsupported format 'xml'")
+
+	_, err = config.Marshal("value", "env")
+	require.ErrorContains(t, err, "config must be a struct")
+
+	_, err = config.Marshal(map[string]any{"fn": func() {}}, "json")
+	require.Error(t, err)
+
+	// INI can't hold maps, so they aren't dropped silently.
+	type iniMaps struct {
+		Name   string            `ini:"name"`
+		Labels map[string]string `ini:"labels"`
+	}
+	_, err = config.Marshal(iniMaps{Name: "svc"}, "ini")
+	require.ErrorContains(t, err, "field 'labels': maps aren't supported by INI")
+}
@@ -1,70 +1,307 @@
 package config
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+)
 
-func tryDefault(val, def any) any {
-	if val == nil {
-		return def
+const mergeTag = "merge"
+
+// Merge strategies that can be set for a field with `merge` tag.
+const (
+	// mergeReplace uses the value of the source with higher priority if it's set.
+	// It's the default strategy for all types except structs, maps and pointers to structs.
+	mergeReplace = "replace"
+	// mergeAppend concatenates slices.
+	mergeAppend = "append"
+	// mergeUnion concatenates slices skipping duplicates, or combines keys of maps
+	// without merging their values.
+	mergeUnion = "union"
+	// mergeDeep merges structs field by field and maps key by key. It's the default
+	// strategy for structs, maps and pointers to structs.
+	mergeDeep = "deep"
+	// mergeKeep uses the value of the first source if it's set.
+	mergeKeep = "keep"
+)
+
+// mergeLeft function merges the zero values of the left struct
+// with corresponding values from the right struct, so set values of left win.
+// Structs, maps and pointers to structs are merged deeply, strategies of fields
+// can be changed with `merge` tag. Private fields will be ignored.
+// Returns the merged struct.
+func mergeLeft[T any](left, right T) T {
+	return mergeValues(left, right, merger{leftWins: true})
+}
+
+// mergeRight merges left and right like mergeLeft, but set values of right win.
+// It's used to merge sources in order, where the later source wins.
+func mergeRight[T any](left, right T) T {
+	return mergeValues(left, right, merger{})
+}
+
+// mergeSource merges right decoded from a source into left. Fields of right that were
+// present in the source win, even if they are zero.
+func mergeSource[T any](left, right T, present presence) T {
+	return mergeValues(left, right, merger{present: present})
+}
+
+func mergeValues[T any](left, right T, m merger) T {
+	merged := m.merge(reflect.ValueOf(&left).Elem(), reflect.ValueOf(&right).Elem(), "", "")
+
+	var res T
+	reflect.ValueOf(&res).Elem().Set(merged)
+	return res
+}
+
+// merger merges values of two sources. Left is the first source, right is the second one.
+// Set fields of right are determined by present if it isn't nil.
+type merger struct {
+	leftWins bool
+	present  presence
+}
+
+// untracked returns merger for values that aren't struct fields, e.g. values of maps.
+func (m merger) untracked() merger {
+	m.present = nil
+	return m
+}
+
+func (m merger) merge(left, right reflect.Value, path, strategy string) reflect.Value {
+	if strategy == "" {
+		strategy = defaultMergeStrategy(left.Type())
 	}
 
-	if reflect.ValueOf(val).IsZero() {
-		return def
+	switch strategy {
+	case mergeKeep:
+		if !left.IsZero() {
+			return left
+		}
+		return right
+	case mergeAppend:
+		if left.Kind() == reflect.Slice {
+			return m.appendSlices(left, right, path, false)
+		}
+	case mergeUnion:
+		switch left.Kind() {
+		case reflect.Slice:
+			return m.appendSlices(left, right, path, true)
+		case reflect.Map:
+			return m.mergeMaps(left, right, path, false)
+		}
+	case mergeDeep:
+		switch left.Kind() {
+		case reflect.Struct:
+			if hasExportedFields(left.Type()) {
+				res := reflect.New(left.Type()).Elem()
+				res.Set(left)
+				m.mergeStruct(res, left, right, path)
+				return res
+			}
+		case reflect.Pointer:
+			if left.IsNil() || right.IsNil() {
+				return m.pick(left, right, path)
+			}
+
+			res := reflect.New(left.Type().Elem())
+			res.Elem().Set(m.merge(left.Elem(), right.Elem(), path, mergeDeep))
+			return res
+		case reflect.Map:
+			return m.mergeMaps(left, right, path, true)
+		case reflect.Interface:
+			if !left.IsNil() && !right.IsNil() && left.Elem().Type() == right.Elem().Type() {
+				res := reflect.New(left.Type()).Elem()
+				res.Set(m.untracked().merge(left.Elem(), right.Elem(), path, ""))
+				return res
+			}
+		}
 	}
 
-	return val
+	return m.pick(left, right, path)
 }
 
-// tryValueOrDefault tries to use default value if val is not defined.
-func tryValueOrDefault[T any](val, def T) T {
-	return tryDefault(val, def).(T)
+// pick returns the value of the source with higher priority if it's set.
+func (m merger) pick(left, right reflect.Value, path string) reflect.Value {
+	if m.leftWins {
+		if !left.IsZero() {
+			return left
+		}
+		return right
+	}
+
+	if m.present.isSet(right, path) {
+		return right
+	}
+	return left
 }
 
-// mergeLeft function merges the zero values of the left struct
-// with corresponding values from the right struct.
-// For types Chan, Func, Interface, Map, Pointer, Slice, UnsafePointer
-// only the pointer is copied. Private fields will be ignored.
-// Returns the merged struct.
-func mergeLeft[T any](left, right T) T {
-	lVal := reflect.ValueOf(left)
-	rVal := reflect.ValueOf(right)
+// mergeStruct sets exported fields of dst to merged fields of left and right.
+// Fields of embedded structs with unexported type are merged too, because they are promoted.
+func (m merger) mergeStruct(dst, left, right reflect.Value, path string) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath := joinPath(path, field.Name)
 
-	if lVal.Kind() != reflect.Struct {
-		return tryValueOrDefault(left, right)
+		if !field.IsExported() {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				m.mergeStruct(dst.Field(i), left.Field(i), right.Field(i), fieldPath)
+			}
+			continue
+		}
+
+		dst.Field(i).Set(m.merge(left.Field(i), right.Field(i), fieldPath, field.Tag.Get(mergeTag)))
+	}
+}
+
+func (m merger) mergeMaps(left, right reflect.Value, path string, deep bool) reflect.Value {
+	if left.Len() == 0 {
+		return m.pick(left, right, path)
+	}
+	if right.Len() == 0 {
+		return left
 	}
 
-	mergedVal := reflect.New(reflect.TypeOf(left))
-	if merged, ok := mergedVal.Interface().(*T); ok {
-		*merged = left
+	res := reflect.MakeMapWithSize(left.Type(), left.Len()+right.Len())
+	iter := left.MapRange()
+	for iter.Next() {
+		res.SetMapIndex(iter.Key(), iter.Value())
 	}
-	mergedVal = reflect.Indirect(mergedVal)
 
-	fields := lVal.NumField()
-	for i := 0; i < fields; i++ {
-		if !lVal.Type().Field(i).IsExported() {
-			continue
+	iter = right.MapRange()
+	for iter.Next() {
+		leftVal := left.MapIndex(iter.Key())
+		switch {
+		case !leftVal.IsValid():
+			res.SetMapIndex(iter.Key(), iter.Value())
+		case deep:
+			res.SetMapIndex(iter.Key(), m.untracked().merge(leftVal, iter.Value(), "", ""))
+		case !m.leftWins:
+			res.SetMapIndex(iter.Key(), iter.Value())
 		}
+	}
 
-		lfield := lVal.Field(i)
-		rfield := rVal.Field(i)
-		mfield := mergedVal.Field(i)
-		if !mfield.CanSet() {
-			continue
+	return res
+}
+
+// appendSlices returns items of left followed by items of right.
+// If unique is true, items that are already in the result are skipped.
+func (m merger) appendSlices(left, right reflect.Value, path string, unique bool) reflect.Value {
+	if left.Len() == 0 && !unique {
+		return m.pick(left, right, path)
+	}
+	if right.Len() == 0 && !unique {
+		return left
+	}
+
+	res := reflect.MakeSlice(left.Type(), 0, left.Len()+right.Len())
+	for _, items := range []reflect.Value{left, right} {
+		for i := 0; i < items.Len(); i++ {
+			if unique && containsValue(res, items.Index(i)) {
+				continue
+			}
+			res = reflect.Append(res, items.Index(i))
+		}
+	}
+
+	if res.Len() == 0 {
+		return m.pick(left, right, path)
+	}
+
+	return res
+}
+
+func containsValue(items, v reflect.Value) bool {
+	for i := 0; i < items.Len(); i++ {
+		if reflect.DeepEqual(items.Index(i).Interface(), v.Interface()) {
+			return true
 		}
+	}
+
+	return false
+}
 
-		var mergedField any
-		if lfield.Kind() == reflect.Struct {
-			mergedField = mergeLeft(lfield.Interface(), rfield.Interface())
-		} else {
-			mergedField = tryDefault(lfield.Interface(), rfield.Interface())
+func defaultMergeStrategy(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return mergeDeep
+	case reflect.Pointer:
+		if t.Elem().Kind() == reflect.Struct {
+			return mergeDeep
 		}
+	}
+
+	return mergeReplace
+}
+
+// checkMergeTags checks that strategies from `merge` tags of struct t are known and
+// applicable to types of the fields.
+func checkMergeTags(t reflect.Type) error {
+	return checkTypeMergeTags(t, "", map[reflect.Type]bool{})
+}
 
-		if mergedField == nil {
+func checkTypeMergeTags(t reflect.Type, path string, visited map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || visited[t] {
+		return nil
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
 			continue
 		}
 
-		mergedVal := reflect.ValueOf(mergedField)
-		mfield.Set(mergedVal)
+		fieldPath := joinPath(path, field.Name)
+		strategy, ok := field.Tag.Lookup(mergeTag)
+		if ok && !mergeApplicable(strategy, field.Type) {
+			return fmt.Errorf("field '%s': merge strategy '%s' isn't applicable to %s", fieldPath, strategy, field.Type)
+		}
+
+		// Defaults are set before the first source, so the field would never change.
+		if strategy == mergeKeep && field.Tag.Get(defaultTag) != "" {
+			return fmt.Errorf("field '%s': merge strategy '%s' can't be used with `%s` tag", fieldPath, mergeKeep, defaultTag)
+		}
+
+		if err := checkTypeMergeTags(field.Type, fieldPath, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mergeApplicable(strategy string, t reflect.Type) bool {
+	switch strategy {
+	case mergeReplace, mergeKeep:
+		return true
+	case mergeAppend:
+		return t.Kind() == reflect.Slice
+	case mergeUnion:
+		return t.Kind() == reflect.Slice || t.Kind() == reflect.Map
+	case mergeDeep:
+		return defaultMergeStrategy(t) == mergeDeep
+	default:
+		return false
+	}
+}
+
+// hasExportedFields reports whether struct type t has exported fields.
+// Structs without them (e.g. time.Time) are merged as a whole.
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.IsExported() {
+			return true
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && hasExportedFields(field.Type) {
+			return true
+		}
 	}
 
-	return mergedVal.Interface().(T)
+	return false
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 	"unsafe"
 
@@ -200,3 +201,127 @@ func mergeWith[T any](left, right, expected T) (f func(t *testing.T)) {
 		assert.Equal(t, expected, mergeLeft(left, right))
 	}
 }
+
+func TestMergeStrategies(t *testing.T) {
+	type testPool struct {
+		Min int
+		Max int
+	}
+
+	type testDB struct {
+		Host string
+		Pool *testPool
+	}
+
+	type testStrategies struct {
+		Hosts    []string          `merge:"append"`
+		Tags     []string          `merge:"union"`
+		Plugins  []string          // replace by default
+		Labels   map[string]string // deep by default
+		Env      map[string]string `merge:"replace"`
+		Backends map[string]testDB
+		Owners   map[string]testDB `merge:"union"`
+		Name     string            `merge:"keep"`
+		Debug    *bool
+		DB       *testDB
+		Extra    map[string]any
+	}
+
+	falseVal, trueVal := false, true
+
+	first := testStrategies{
+		Hosts:    []string{"a", "b"},
+		Tags:     []string{"x", "y"},
+		Plugins:  []string{"p1"},
+		Labels:   map[string]string{"team": "core", "env": "dev"},
+		Env:      map[string]string{"A": "1"},
+		Backends: map[string]testDB{"main": {Host: "first", Pool: &testPool{Min: 1}}},
+		Owners:   map[string]testDB{"main": {Host: "first"}},
+		Name:     "first",
+		Debug:    &trueVal,
+		DB:       &testDB{Host: "first", Pool: &testPool{Min: 1, Max: 2}},
+		Extra:    map[string]any{"nested": map[string]any{"a": 1, "b": 2}},
+	}
+
+	second := testStrategies{
+		Hosts:    []string{"c"},
+		Tags:     []string{"y", "z"},
+		Plugins:  []string{"p2"},
+		Labels:   map[string]string{"env": "prod"},
+		Env:      map[string]string{"B": "2"},
+		Backends: map[string]testDB{"main": {Pool: &testPool{Max: 10}}, "replica": {Host: "second"}},
+		Owners:   map[string]testDB{"main": {Host: "second"}, "other": {Host: "second"}},
+		Name:     "second",
+		Debug:    &falseVal,
+		DB:       &testDB{Pool: &testPool{Max: 20}},
+		Extra:    map[string]any{"nested": map[string]any{"b": 3}},
+	}
+
+	t.Run("right wins", func(t *testing.T) {
+		merged := mergeRight(first, second)
+		assert.Equal(t, testStrategies{
+			Hosts:   []string{"a", "b", "c"},
+			Tags:    []string{"x", "y", "z"},
+			Plugins: []string{"p2"},
+			Labels:  map[string]string{"team": "core", "env": "prod"},
+			Env:     map[string]string{"B": "2"},
+			Backends: map[string]testDB{
+				"main":    {Host: "first", Pool: &testPool{Min: 1, Max: 10}},
+				"replica": {Host: "second"},
+			},
+			Owners: map[string]testDB{"main": {Host: "second"}, "other": {Host: "second"}},
+			Name:   "first",
+			Debug:  &falseVal,
+			DB:     &testDB{Host: "first", Pool: &testPool{Min: 1, Max: 20}},
+			Extra:  map[string]any{"nested": map[string]any{"a": 1, "b": 3}},
+		}, merged)
+
+		// Inputs aren't modified.
+		assert.Equal(t, map[string]string{"team": "core", "env": "dev"}, first.Labels)
+		assert.Equal(t, &testPool{Min: 1, Max: 2}, first.DB.Pool)
+	})
+
+	t.Run("left wins", func(t *testing.T) {
+		merged := mergeLeft(first, second)
+		assert.Equal(t, []string{"a", "b", "c"}, merged.Hosts)
+		assert.Equal(t, []string{"p1"}, merged.Plugins)
+		assert.Equal(t, map[string]string{"team": "core", "env": "dev"}, merged.Labels)
+		assert.Equal(t, map[string]testDB{"main": {Host: "first"}, "other": {Host: "second"}}, merged.Owners)
+		assert.Equal(t, &trueVal, merged.Debug)
+		assert.Equal(t, &testDB{Host: "first", Pool: &testPool{Min: 1, Max: 2}}, merged.DB)
+	})
+
+	t.Run("zero values", func(t *testing.T) {
+		merged := mergeRight(first, testStrategies{})
+		assert.Equal(t, first, merged)
+
+		merged = mergeRight(testStrategies{}, second)
+		assert.Equal(t, second, merged)
+	})
+}
+
+func TestCheckMergeTags(t *testing.T) {
+	type valid struct {
+		Hosts  []string          `merge:"append"`
+		Labels map[string]string `merge:"union"`
+		Port   int               `merge:"keep"`
+	}
+	assert.NoError(t, checkMergeTags(reflect.TypeOf(valid{})))
+
+	type unknown struct {
+		Hosts []string `merge:"prepend"`
+	}
+	assert.ErrorContains(t, checkMergeTags(reflect.TypeOf(unknown{})), "field 'Hosts': merge strategy 'prepend'")
+
+	type inapplicable struct {
+		Nested struct {
+			Port int `merge:"append"`
+		}
+	}
+	assert.ErrorContains(t, checkMergeTags(reflect.TypeOf(inapplicable{})), "field 'Nested.Port': merge strategy 'append' isn't applicable to int")
+
+	type keepDefault struct {
+		Port int `merge:"keep" default:"8080"`
+	}
+	assert.ErrorContains(t, checkMergeTags(reflect.TypeOf(keepDefault{})), "field 'Port': merge strategy 'keep' can't be used with `default` tag")
+}
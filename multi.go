@@ -1,27 +1,89 @@
 package config
 
 import (
-	"encoding/json"
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
-	"io"
+	"reflect"
+	"slices"
 
 	"github.com/MordaTeam/go-toolbox/options"
 )
 
 type multiConfigurator[T any] struct {
-	configurators []configurator[T]
+	layers []layer[T]
+	// trace of the last built config for Explain.
+	trace *buildTrace[T]
+}
+
+// layer is a configurator with name and priority. Pos is the position of adding,
+// source is the name of the provider.
+type layer[T any] struct {
+	name      string
+	priority  int
+	pos       int
+	source    string
+	optional  bool
+	configure configurator[T]
+}
+
+// sourceName returns the name of the layer for provenance: its name, the name of
+// the provider or its position.
+func (l layer[T]) sourceName() string {
+	switch {
+	case l.name != "":
+		return l.name
+	case l.source != "":
+		return l.source
+	default:
+		return fmt.Sprintf("pos %d", l.pos)
+	}
+}
+
+// label returns the name of the layer for errors or its position if the layer has no name.
+func (l layer[T]) label() string {
+	if l.name == "" {
+		return fmt.Sprintf("pos %d", l.pos)
+	}
+
+	return fmt.Sprintf("layer '%s'", l.name)
 }
 
 // OneOf builds config if at least once configurator created config successfully.
+// Configurators are tried in order, the first config that passes validation is returned.
 func (m *multiConfigurator[T]) OneOf() (cfg T, err error) {
-	for i, c := range m.configurators {
-		cerr := c(&cfg)
-		if cerr == nil {
-			return cfg, nil
+	return m.OneOfContext(context.Background())
+}
+
+// OneOfContext builds config like OneOf. Cancellation and deadline of ctx are propagated
+// to providers like in NewContext.
+func (m *multiConfigurator[T]) OneOfContext(ctx context.Context) (cfg T, err error) {
+	m.trace = nil
+	if err := m.init(&cfg); err != nil {
+		return cfg, err
+	}
+
+	for _, l := range m.layers {
+		src, present, commit, cerr := l.configure(ctx)
+		if cerr != nil {
+			err = errors.Join(err, fmt.Errorf("in %s: %w", l.label(), cerr))
+			continue
+		}
+
+		merged := mergeSource(cfg, src, present)
+		if verr := validateConfig(&merged); verr != nil {
+			err = errors.Join(err, fmt.Errorf("in %s: %w", l.label(), verr))
+			continue
 		}
 
-		err = errors.Join(err, fmt.Errorf("in pos %d: %w", i, cerr))
+		trace := &buildTrace[T]{defaults: cfg}
+		trace.add(l, src, present)
+		trace.cfg = merged
+		m.trace = trace
+
+		commit()
+		return merged, nil
 	}
 
 	return cfg, fmt.Errorf("create config from configurators: %w", err)
@@ -36,10 +98,47 @@ func (m *multiConfigurator[T]) OneOfFill(cfg *T) (err error) {
 
 // AllOf builds config if all configurators created config successfully.
 func (m *multiConfigurator[T]) AllOf() (cfg T, err error) {
-	for i, c := range m.configurators {
-		if cerr := c(&cfg); cerr != nil {
-			err = errors.Join(err, fmt.Errorf("in pos %d: %w", i, cerr))
+	return m.AllOfContext(context.Background())
+}
+
+// AllOfContext builds config like AllOf. Cancellation and deadline of ctx are propagated
+// to providers like in NewContext.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//
+//	cfg, err := config.Multi[MyConfig]().
+//		Add(config.FromFile("config.json")).
+//		Add(config.FromConsul("service/config")).
+//		AllOfContext(ctx)
+func (m *multiConfigurator[T]) AllOfContext(ctx context.Context) (cfg T, err error) {
+	return m.allOf(ctx, m.layers)
+}
+
+func (m *multiConfigurator[T]) allOf(ctx context.Context, layers []layer[T]) (cfg T, err error) {
+	m.trace = nil
+	if err := m.init(&cfg); err != nil {
+		return cfg, err
+	}
+
+	var commits []func()
+	trace := &buildTrace[T]{defaults: cfg}
+	for _, l := range layers {
+		src, present, commit, cerr := l.configure(ctx)
+		if cerr != nil {
+			// Missing secrets of existing sources aren't skipped.
+			var refErr *ReferenceError
+			if l.optional && isSourceNotFound(cerr) && !errors.As(cerr, &refErr) {
+				continue
+			}
+
+			err = errors.Join(err, fmt.Errorf("in %s: %w", l.label(), cerr))
+			continue
 		}
+
+		commits = append(commits, commit)
+		trace.add(l, src, present)
+		cfg = mergeSource(cfg, src, present)
 	}
 
 	if err != nil {
@@ -47,9 +146,52 @@ func (m *multiConfigurator[T]) AllOf() (cfg T, err error) {
 		return empty, fmt.Errorf("create config from configurators: %w", err)
 	}
 
+	if err := validateConfig(&cfg); err != nil {
+		var empty T
+		return empty, err
+	}
+
+	for _, commit := range commits {
+		commit()
+	}
+
+	trace.cfg = cfg
+	m.trace = trace
 	return cfg, nil
 }
 
+// Explain returns provenance of the config built by the last call of OneOf, AllOf or Layered
+// (or their Fill variants): for each leaf field, its value, the source that set it and
+// values of other sources that it shadowed. Sources are named by layer names, then by
+// names of providers (see SourceNamer), then by positions.
+// It returns an error if the last build failed or there was no build.
+//
+//	cfg, err := multi.AllOf()
+//	// ...
+//	prov, err := multi.Explain()
+//	// ...
+//	fmt.Print(prov) // or json.NewEncoder(w).Encode(prov)
+func (m *multiConfigurator[T]) Explain() (Provenance, error) {
+	if m.trace == nil {
+		return nil, errors.New("config isn't built")
+	}
+
+	return m.trace.provenance(), nil
+}
+
+// init checks merge tags of config and sets default values.
+func (m *multiConfigurator[T]) init(cfg *T) error {
+	if err := checkMergeTags(reflect.TypeOf(cfg).Elem()); err != nil {
+		return fmt.Errorf("merge config: %w", err)
+	}
+
+	if err := setDefaults(cfg); err != nil {
+		return fmt.Errorf("apply defaults: %w", err)
+	}
+
+	return nil
+}
+
 // AllOfFill fills the provided config pointer with the result of AllOf method.
 // Note that all fields of cfg will be overridden by new values.
 func (m *multiConfigurator[T]) AllOfFill(cfg *T) (err error) {
@@ -57,9 +199,109 @@ func (m *multiConfigurator[T]) AllOfFill(cfg *T) (err error) {
 	return
 }
 
+// Layered builds config from layers ordered by priority: each layer is decoded into its own
+// config, then layers are merged from the lowest priority to the highest one, so the layer
+// with the highest priority wins for every field present in its data. Layers with equal
+// priorities are merged in order of adding. All layers must create config successfully.
+//
+// Layers are added with AddLayer. Configurators added with Add are unnamed layers with priority 0.
+//
+//	cfg, err := config.Multi[MyConfig]().
+//		AddLayer("env", 30, config.FromEnv(), config.WithDecoder(config.EnvDecoder)).
+//		AddLayer("defaults", 0, config.FromFile("defaults.json")).
+//		AddLayer("file", 10, config.FromFile("config.json")).
+//		Layered()
+func (m *multiConfigurator[T]) Layered() (cfg T, err error) {
+	return m.LayeredContext(context.Background())
+}
+
+// LayeredContext builds config like Layered. Cancellation and deadline of ctx are propagated
+// to providers like in NewContext.
+func (m *multiConfigurator[T]) LayeredContext(ctx context.Context) (cfg T, err error) {
+	names := map[string]bool{}
+	for _, l := range m.layers {
+		if l.name == "" {
+			continue
+		}
+
+		if names[l.name] {
+			return cfg, fmt.Errorf("duplicate layer name '%s'", l.name)
+		}
+		names[l.name] = true
+	}
+
+	layers := slices.Clone(m.layers)
+	slices.SortStableFunc(layers, func(a, b layer[T]) int {
+		return cmp.Compare(a.priority, b.priority)
+	})
+
+	return m.allOf(ctx, layers)
+}
+
+// LayeredFill fills the provided config pointer with the result of Layered method.
+// Note that all fields of cfg will be overridden by new values.
+func (m *multiConfigurator[T]) LayeredFill(cfg *T) (err error) {
+	*cfg, err = m.Layered()
+	return
+}
+
 // Add adds configurator to build config.
 func (m *multiConfigurator[T]) Add(provider ConfigProvider, opts ...options.Option[cfgOpts]) *multiConfigurator[T] {
-	m.configurators = append(m.configurators, newConfigurator[T](provider, opts...))
+	return m.addLayer("", 0, false, provider, opts...)
+}
+
+// AddOptional adds configurator that is skipped by AllOf and Layered if its source doesn't
+// exist: the provider returns an error wrapping os.ErrNotExist (e.g. missing file) or
+// ErrSourceNotFound (e.g. missing Consul key). Other errors (e.g. parse failure or permission
+// denied) still fail the build. For OneOf it's the same as Add.
+//
+//	cfg, err := config.Multi[MyConfig]().
+//		Add(config.FromFile("base.json")).
+//		AddOptional(config.FromFile("local.json")).
+//		Add(config.FromEnv(), config.WithDecoder(config.EnvDecoder)).
+//		AllOf()
+func (m *multiConfigurator[T]) AddOptional(provider ConfigProvider, opts ...options.Option[cfgOpts]) *multiConfigurator[T] {
+	return m.addLayer("", 0, true, provider, opts...)
+}
+
+// AddLayer adds configurator as a layer with name and priority used by Layered.
+// The name is used in errors, so it's recommended to be unique.
+// For OneOf and AllOf it's the same as Add.
+func (m *multiConfigurator[T]) AddLayer(
+	name string,
+	priority int,
+	provider ConfigProvider,
+	opts ...options.Option[cfgOpts],
+) *multiConfigurator[T] {
+	return m.addLayer(name, priority, false, provider, opts...)
+}
+
+// AddOptionalLayer adds layer like AddLayer, but the layer is skipped if its source doesn't
+// exist like in AddOptional.
+func (m *multiConfigurator[T]) AddOptionalLayer(
+	name string,
+	priority int,
+	provider ConfigProvider,
+	opts ...options.Option[cfgOpts],
+) *multiConfigurator[T] {
+	return m.addLayer(name, priority, true, provider, opts...)
+}
+
+func (m *multiConfigurator[T]) addLayer(
+	name string,
+	priority int,
+	optional bool,
+	provider ConfigProvider,
+	opts ...options.Option[cfgOpts],
+) *multiConfigurator[T] {
+	m.layers = append(m.layers, layer[T]{
+		name:      name,
+		priority:  priority,
+		pos:       len(m.layers),
+		source:    sourceName(provider),
+		optional:  optional,
+		configure: newConfigurator[T](provider, opts...),
+	})
 	return m
 }
 
@@ -67,6 +309,10 @@ func (m *multiConfigurator[T]) Add(provider ConfigProvider, opts ...options.Opti
 // Use method .Add to add configurator, then call .OneOf or .AllOf method to build config.
 //
 // NOTE: result depends on providers order. If parameter is provided by multiple of them, the last wins.
+// Each configurator decodes into a new config, which is merged into the result like in Fill:
+// fields present in the data of the later configurator win, even if they are zero.
+// Values from `default` tag are set before the first configurator, so they have the lowest priority.
+// The built config is validated like in New.
 //
 // Example
 //
@@ -79,31 +325,11 @@ func Multi[T any]() *multiConfigurator[T] {
 	return &multiConfigurator[T]{}
 }
 
-type configurator[T any] func(cfg *T) error
+// configurator decodes a config of a layer. Commit is like in decodeConfigs.
+type configurator[T any] func(ctx context.Context) (cfg T, present presence, commit func(), err error)
 
 func newConfigurator[T any](provider ConfigProvider, opts ...options.Option[cfgOpts]) configurator[T] {
-	return func(cfg *T) error {
-		r, err := provider.ProvideConfig()
-		if err != nil {
-			return fmt.Errorf("provide config: %w", err)
-		}
-
-		cfgOpts := cfgOpts{
-			newDec: func(r io.Reader) Decoder {
-				return json.NewDecoder(r)
-			},
-		}
-
-		if err := options.ApplyOptions(&cfgOpts, opts...); err != nil {
-			return fmt.Errorf("init options: %w", err)
-		}
-
-		dec := cfgOpts.newDec(r)
-		err = dec.Decode(cfg)
-		if err != nil {
-			return fmt.Errorf("decode config: %w", err)
-		}
-
-		return nil
+	return func(ctx context.Context) (T, presence, func(), error) {
+		return newSource[T](ctx, provider, opts...)
 	}
 }
@@ -3,6 +3,8 @@ package config_test
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"testing/iotest"
 
@@ -122,3 +124,135 @@ func TestMulti_OneOf(t *testing.T) {
 	r.Error(err)
 	r.Equal(testMultiConfig{}, cfg)
 }
+
+func TestMulti_MergeStrategies(t *testing.T) {
+	type mergeConfig struct {
+		Debug   *bool             `json:"debug" default:"true"`
+		Hosts   []string          `json:"hosts" merge:"append"`
+		Labels  map[string]string `json:"labels" default:"team:core,env:dev"`
+		Plugins []string          `json:"plugins" default:"p1,p2"`
+	}
+
+	cfg, err := config.Multi[mergeConfig]().
+		Add(config.FromReader(strings.NewReader(`{"hosts": ["a"], "labels": {"env": "stage"}}`))).
+		Add(config.FromReader(strings.NewReader(`{"debug": false, "hosts": ["b"], "plugins": ["p3"]}`))).
+		AllOf()
+	require.NoError(t, err)
+
+	require.NotNil(t, cfg.Debug)
+	require.False(t, *cfg.Debug, "explicit false overrides default")
+	require.Equal(t, []string{"a", "b"}, cfg.Hosts)
+	require.Equal(t, map[string]string{"team": "core", "env": "stage"}, cfg.Labels)
+	require.Equal(t, []string{"p3"}, cfg.Plugins)
+
+	type invalidConfig struct {
+		Port int `json:"port" merge:"append"`
+	}
+
+	_, err = config.Multi[invalidConfig]().Add(config.FromReader(strings.NewReader(`{}`))).AllOf()
+	require.ErrorContains(t, err, "merge strategy 'append' isn't applicable to int")
+}
+
+func TestMulti_Layered(t *testing.T) {
+	type layeredConfig struct {
+		Host    string `json:"host"`
+		Port    int    `json:"port" default:"8080"`
+		Debug   bool   `json:"debug"`
+		Workers int    `json:"workers"`
+	}
+
+	t.Run("priorities", func(t *testing.T) {
+		cfg, err := config.Multi[layeredConfig]().
+			AddLayer("env", 30, config.FromReader(strings.NewReader(`{"debug": false, "workers": 0}`))).
+			AddLayer("defaults", 0, config.FromReader(strings.NewReader(`{"host": "localhost", "debug": true, "workers": 4}`))).
+			AddLayer("file", 10, config.FromReader(strings.NewReader(`{"host": "example.com", "port": 9090}`))).
+			Layered()
+		require.NoError(t, err)
+		require.Equal(t, layeredConfig{Host: "example.com", Port: 9090}, cfg)
+	})
+
+	t.Run("equal priorities in order of adding", func(t *testing.T) {
+		var cfg layeredConfig
+		err := config.Multi[layeredConfig]().
+			Add(config.FromReader(strings.NewReader(`{"host": "first", "workers": 1}`))).
+			AddLayer("second", 0, config.FromReader(strings.NewReader(`{"host": "second"}`))).
+			LayeredFill(&cfg)
+		require.NoError(t, err)
+		require.Equal(t, layeredConfig{Host: "second", Port: 8080, Workers: 1}, cfg)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := config.Multi[layeredConfig]().
+			AddLayer("file", 10, config.FromReader(strings.NewReader(`{"host": "a"}`))).
+			AddLayer("broken", 20, config.FromReader(strings.NewReader(`{"host": `))).
+			Add(config.FromReader(iotest.ErrReader(io.ErrUnexpectedEOF))).
+			Layered()
+		require.ErrorContains(t, err, "in layer 'broken'")
+		require.ErrorContains(t, err, "in pos 2")
+
+		_, err = config.Multi[layeredConfig]().
+			AddLayer("file", 10, config.FromReader(strings.NewReader(`{}`))).
+			AddLayer("file", 20, config.FromReader(strings.NewReader(`{}`))).
+			Layered()
+		require.ErrorContains(t, err, "duplicate layer name 'file'")
+	})
+}
+
+func TestMulti_AddOptional(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, "base.json", `{"foo": "base", "bar": "base"}`)
+
+	t.Run("missing source is skipped", func(t *testing.T) {
+		cfg, err := config.Multi[testMultiConfig]().
+			Add(config.FromFile(base)).
+			AddOptional(config.FromFile(filepath.Join(dir, "local.json"))).
+			AddOptionalLayer("override", 10, config.FromFile(filepath.Join(dir, "override.json"))).
+			AllOf()
+		require.NoError(t, err)
+		require.Equal(t, testMultiConfig{Foo: "base", Bar: "base"}, cfg)
+	})
+
+	t.Run("existing source is merged", func(t *testing.T) {
+		local := writeFile(t, "local.json", `{"bar": "local"}`)
+
+		cfg, err := config.Multi[testMultiConfig]().
+			Add(config.FromFile(base)).
+			AddOptional(config.FromFile(local)).
+			AllOf()
+		require.NoError(t, err)
+		require.Equal(t, testMultiConfig{Foo: "base", Bar: "local"}, cfg)
+	})
+
+	t.Run("parse error fails", func(t *testing.T) {
+		broken := writeFile(t, "broken.json", `{"bar": `)
+
+		_, err := config.Multi[testMultiConfig]().
+			Add(config.FromFile(base)).
+			AddOptional(config.FromFile(broken)).
+			AllOf()
+		require.ErrorContains(t, err, "in pos 1")
+	})
+
+	t.Run("permission denied fails", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root ignores file permissions")
+		}
+
+		denied := writeFile(t, "denied.json", `{"bar": "denied"}`)
+		require.NoError(t, os.Chmod(denied, 0o000))
+
+		_, err := config.Multi[testMultiConfig]().
+			Add(config.FromFile(base)).
+			AddOptional(config.FromFile(denied)).
+			AllOf()
+		require.ErrorIs(t, err, os.ErrPermission)
+	})
+
+	t.Run("missing required source fails", func(t *testing.T) {
+		_, err := config.Multi[testMultiConfig]().
+			Add(config.FromFile(base)).
+			Add(config.FromFile(filepath.Join(dir, "local.json"))).
+			AllOf()
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/MordaTeam/go-toolbox/options"
+)
+
+// Sentinel values that the probe config is filled with before decoding. Decoders overwrite
+// only fields present in the data, so fields that keep the sentinel weren't present.
+const (
+	sentinelString = "\x00go-config-sentinel\x00"
+	sentinelOffset = 42
+	sentinelFloat  = -1.0 / (1 << 15)
+)
+
+// presence holds Go paths of struct fields (e.g. "DB.Pool") that were present in decoded data.
+// Paths that aren't in presence are checked by zero value.
+type presence map[string]bool
+
+// isSet reports whether value v at path was present in data or non-zero if it's unknown.
+func (p presence) isSet(v reflect.Value, path string) bool {
+	if present, ok := p[path]; ok {
+		return present
+	}
+
+	return !v.IsZero()
+}
+
+// newSource decodes data provided by provider into a new config without defaults and
+// reports which fields were present in the data. Commit is like in decodeConfigs.
+func newSource[T any](ctx context.Context, provider ConfigProvider, opts ...options.Option[cfgOpts]) (cfg T, present presence, commit func(), err error) {
+	cfgOpts, err := newCfgOpts(opts...)
+	if err != nil {
+		return cfg, nil, nil, err
+	}
+
+	return decodeWithPresence[T](ctx, provider, cfgOpts)
+}
+
+// decodeWithPresence decodes data provided by provider into a new config and reports which
+// fields were present in the data. Commit is like in decodeConfigs.
+//
+// The data is decoded twice: into zero config and into probe config filled with sentinel
+// values. A field was present if it has the same value in both configs. Slices and maps
+// are present if they aren't nil, so explicit empty values are present too. Other fields
+// that can't hold sentinels (e.g. time.Time) are present if they aren't zero.
+func decodeWithPresence[T any](ctx context.Context, provider ConfigProvider, cfgOpts cfgOpts) (cfg T, present presence, commit func(), err error) {
+	var probe T
+	v, probeVal := reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(&probe).Elem()
+
+	// Configs that aren't structs (e.g. maps) are a single leaf.
+	isStruct := v.Kind() == reflect.Struct
+	if isStruct {
+		setSentinels(probeVal, map[reflect.Type]bool{})
+	}
+
+	commit, err = decodeConfigs(ctx, provider, cfgOpts, &cfg, &probe)
+	if err != nil {
+		return cfg, nil, nil, err
+	}
+
+	present = presence{}
+	if isStruct {
+		collectPresence(v, probeVal, "", present)
+	} else {
+		present[""] = leafPresent(v)
+	}
+
+	if err := cfgOpts.resolveReferences(ctx, &cfg); err != nil {
+		return cfg, nil, nil, err
+	}
+
+	return cfg, present, commit, nil
+}
+
+// setSentinels sets sentinel values to fields of struct v. Nil pointers are allocated.
+// Types in stack are skipped to stop on recursive types.
+func setSentinels(v reflect.Value, stack map[reflect.Type]bool) {
+	if stack[v.Type()] {
+		return
+	}
+	stack[v.Type()] = true
+	defer delete(stack, v.Type())
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldVal := v.Field(i)
+
+		if !field.IsExported() {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				setSentinels(fieldVal, stack)
+			}
+			continue
+		}
+
+		setSentinel(fieldVal, stack)
+	}
+}
+
+func setSentinel(v reflect.Value, stack map[reflect.Type]bool) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if hasExportedFields(v.Type()) {
+			setSentinels(v, stack)
+		}
+	case reflect.Pointer:
+		elem := v.Type().Elem()
+		if !hasSentinel(elem) || stack[elem] {
+			return
+		}
+
+		v.Set(reflect.New(elem))
+		setSentinel(v.Elem(), stack)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(-1<<(v.Type().Bits()-1) + sentinelOffset)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(1<<v.Type().Bits() - 1 - sentinelOffset)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(sentinelFloat)
+	case reflect.String:
+		v.SetString(sentinelString)
+	}
+}
+
+// hasSentinel reports whether values of type t can hold sentinel values.
+func hasSentinel(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return hasExportedFields(t)
+	case reflect.Pointer:
+		return hasSentinel(t.Elem())
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// collectPresence compares decoded config v with decoded probe config and stores presence
+// of fields.
+func collectPresence(v, probe reflect.Value, path string, present presence) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		fieldPath := joinPath(path, field.Name)
+		if !field.IsExported() {
+			collectPresence(v.Field(i), probe.Field(i), fieldPath, present)
+			continue
+		}
+
+		collectFieldPresence(v.Field(i), probe.Field(i), fieldPath, present)
+	}
+}
+
+func collectFieldPresence(v, probe reflect.Value, path string, present presence) {
+	switch {
+	case !hasSentinel(v.Type()):
+		present[path] = leafPresent(v)
+	case v.Kind() == reflect.Struct:
+		collectPresence(v, probe, path, present)
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() || probe.IsNil() {
+			present[path] = !v.IsNil()
+			return
+		}
+
+		if v.Elem().Kind() == reflect.Struct || v.Elem().Kind() == reflect.Pointer {
+			present[path] = true
+			collectFieldPresence(v.Elem(), probe.Elem(), path, present)
+			return
+		}
+
+		present[path] = sameValue(v.Elem(), probe.Elem())
+	default:
+		present[path] = sameValue(v, probe)
+	}
+}
+
+// leafPresent reports whether value v that can't hold sentinels was present in data.
+// Decoders allocate slices and maps present in data, even empty ones, other values are
+// present if they aren't zero.
+func leafPresent(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return !v.IsNil()
+	default:
+		return !v.IsZero()
+	}
+}
+
+// sameValue reports whether scalar values are equal.
+func sameValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.String:
+		return a.String() == b.String()
+	default:
+		return false
+	}
+}
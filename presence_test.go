@@ -0,0 +1,182 @@
+package config_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testPresenceConfig struct {
+	Enabled bool    `json:"enabled" ini:"enabled" env:"ENABLED" default:"true"`
+	Retries int     `json:"retries" ini:"retries" env:"RETRIES" long:"retries" default:"3"`
+	Ratio   float64 `json:"ratio" ini:"ratio" env:"RATIO" long:"ratio" default:"0.5"`
+	Name    string  `json:"name" ini:"name" env:"NAME" long:"name" default:"svc"`
+	Limit   *uint   `json:"limit" ini:"limit" env:"LIMIT" long:"limit" default:"10"`
+
+	DB testPresenceDB `json:"db" ini:"db" group:"db" namespace:"db"`
+}
+
+type testPresenceDB struct {
+	Host string `json:"host" ini:"host" long:"host" default:"localhost"`
+	Port int    `json:"port" ini:"port" long:"port" default:"5432"`
+}
+
+func TestPresence(t *testing.T) {
+	zero := uint(0)
+	tests := []struct {
+		name string
+		data string
+		dec  func(io.Reader) config.Decoder
+		exp  testPresenceConfig
+	}{
+		{
+			name: "json",
+			data: `{"enabled": false, "retries": 0, "ratio": 0, "limit": 0, "db": {"port": 0}}`,
+			dec:  config.DecoderWrap(config.JsonDecoder),
+			exp: testPresenceConfig{
+				Name:  "svc",
+				Limit: &zero,
+				DB:    testPresenceDB{Host: "localhost"},
+			},
+		},
+		{
+			name: "ini",
+			data: "enabled = false\nretries = 0\nratio = 0\nlimit = 0\n[db]\nport = 0\n",
+			dec:  config.DecoderWrap(config.IniDecoder),
+			exp: testPresenceConfig{
+				Name:  "svc",
+				Limit: &zero,
+				DB:    testPresenceDB{Host: "localhost"},
+			},
+		},
+		{
+			name: "env",
+			data: "ENABLED=false\nRETRIES=0\nRATIO=0\nLIMIT=0\n",
+			dec:  config.DecoderWrap(config.DotenvDecoder),
+			exp: testPresenceConfig{
+				Name:  "svc",
+				Limit: &zero,
+				DB:    testPresenceDB{Host: "localhost", Port: 5432},
+			},
+		},
+		{
+			name: "cmdline",
+			data: "--retries 0 --ratio 0 --limit 0 --db.port 0",
+			dec:  config.DecoderWrap(config.CmdlineDecoder),
+			exp: testPresenceConfig{
+				Enabled: true,
+				Name:    "svc",
+				Limit:   &zero,
+				DB:      testPresenceDB{Host: "localhost"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("Fill", func(t *testing.T) {
+				limit := uint(20)
+				cfg := testPresenceConfig{Enabled: true, Retries: 5, Limit: &limit}
+				err := config.Fill(&cfg, config.FromReader(strings.NewReader(tt.data)), config.WithDecoder(tt.dec))
+				require.NoError(t, err)
+				require.Equal(t, tt.exp, cfg)
+			})
+
+			t.Run("Multi", func(t *testing.T) {
+				cfg, err := config.Multi[testPresenceConfig]().
+					Add(config.FromReader(strings.NewReader(`{"retries": 7, "limit": 30}`))).
+					Add(config.FromReader(strings.NewReader(tt.data)), config.WithDecoder(tt.dec)).
+					AllOf()
+				require.NoError(t, err)
+				require.Equal(t, tt.exp, cfg)
+			})
+		})
+	}
+}
+
+func TestPresence_AbsentFieldsKept(t *testing.T) {
+	cfg := testPresenceConfig{Name: "custom", Retries: 5}
+	err := config.Fill(&cfg, config.FromReader(strings.NewReader(`{"db": {"host": "db.local"}}`)))
+	require.NoError(t, err)
+
+	limit := uint(10)
+	require.Equal(t, testPresenceConfig{
+		Enabled: true,
+		Retries: 5,
+		Ratio:   0.5,
+		Name:    "custom",
+		Limit:   &limit,
+		DB:      testPresenceDB{Host: "db.local", Port: 5432},
+	}, cfg)
+}
+
+func TestPresence_EmptyCollections(t *testing.T) {
+	type cfgType struct {
+		Hosts  []string          `json:"hosts" yaml:"hosts"`
+		Labels map[string]string `json:"labels" yaml:"labels" merge:"replace"`
+		Tags   map[string]string `json:"tags" yaml:"tags"`
+	}
+
+	first := `{"hosts": ["a"], "labels": {"a": "1"}, "tags": {"a": "1"}}`
+	tests := []struct {
+		name string
+		data string
+		dec  func(io.Reader) config.Decoder
+	}{
+		{name: "json", data: `{"hosts": [], "labels": {}, "tags": {}}`, dec: config.DecoderWrap(config.JsonDecoder)},
+		{name: "yaml", data: "hosts: []\nlabels: {}\ntags: {}\n", dec: config.DecoderWrap(config.YamlDecoder)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Multi[cfgType]().
+				Add(config.FromReader(strings.NewReader(first))).
+				Add(config.FromReader(strings.NewReader(tt.data)), config.WithDecoder(tt.dec)).
+				AllOf()
+			require.NoError(t, err)
+			require.Empty(t, cfg.Hosts)
+			require.Empty(t, cfg.Labels)
+			// Maps are merged key by key by default, so an empty map doesn't clear them.
+			require.Equal(t, map[string]string{"a": "1"}, cfg.Tags)
+
+			// Absent collections are kept.
+			cfg, err = config.Multi[cfgType]().
+				Add(config.FromReader(strings.NewReader(first))).
+				Add(config.FromReader(strings.NewReader(`{}`))).
+				AllOf()
+			require.NoError(t, err)
+			require.Equal(t, []string{"a"}, cfg.Hosts)
+			require.Equal(t, map[string]string{"a": "1"}, cfg.Labels)
+		})
+	}
+}
+
+func TestPresence_NonStruct(t *testing.T) {
+	data := `{"name": "svc", "port": 8080}`
+	expected := map[string]any{"name": "svc", "port": float64(8080)}
+
+	cfg, err := config.New[map[string]any](config.FromReader(strings.NewReader(data)))
+	require.NoError(t, err)
+	require.Equal(t, expected, cfg)
+
+	filled := map[string]any{"debug": true}
+	err = config.Fill(&filled, config.FromReader(strings.NewReader(data)))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "svc", "port": float64(8080), "debug": true}, filled)
+
+	cfg, err = config.Multi[map[string]any]().
+		Add(config.FromReader(strings.NewReader(`{"name": "first", "debug": true}`))).
+		Add(config.FromReader(strings.NewReader(data))).
+		AllOf()
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "svc", "port": float64(8080), "debug": true}, cfg)
+
+	cfg, err = config.Multi[map[string]any]().
+		Add(config.FromReader(strings.NewReader(data))).
+		OneOf()
+	require.NoError(t, err)
+	require.Equal(t, expected, cfg)
+}
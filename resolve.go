@@ -0,0 +1,465 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/MordaTeam/go-toolbox/options"
+)
+
+// DefaultReferenceDepth is the default maximum depth of references resolved from values
+// of other references.
+const DefaultReferenceDepth = 10
+
+// Resolver resolves references of a scheme into values.
+type Resolver interface {
+	// Resolve returns the value of reference ref without scheme, e.g. "/run/secrets/db"
+	// for "file:/run/secrets/db".
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc is a function that implements Resolver.
+type ResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve implements Resolver.
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// WithReferences is an option that resolves references in string fields of decoded config,
+// so secrets can be kept out of config files:
+//
+//	{
+//		"dsn": "postgres://app:${env:DB_PASSWORD}@db:5432/app",
+//		"password": "${vault:secret/db#password}",
+//		"token": "file:/run/secrets/token"
+//	}
+//
+// References are written as ${scheme:ref} anywhere in a value, $${ is a literal ${.
+// A whole value can be written as scheme:ref without braces if the scheme is registered.
+// Schemes env (EnvResolver) and file (FileResolver) are registered by default, use
+// WithResolver to register more schemes, e.g. ConsulResolver or VaultResolver.
+//
+// Resolved values are resolved again up to DefaultReferenceDepth levels (see
+// WithReferenceDepth). Only references in braces with registered schemes are replaced
+// in them, other text is kept as is, so arbitrary secrets can be resolved.
+//
+// Fields of structs, slices, maps and interfaces are resolved before validation. Failures are
+// joined and reported with ReferenceError holding the reference and the path to the field.
+func WithReferences() options.Option[cfgOpts] {
+	return func(v *cfgOpts) error {
+		if v.resolvers == nil {
+			v.resolvers = map[string]Resolver{
+				"env":  EnvResolver(),
+				"file": FileResolver(),
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithResolver is an option that registers resolver for references of scheme (e.g. "vault")
+// and enables resolution of references like WithReferences. It overrides the resolver that
+// was registered for scheme before.
+func WithResolver(scheme string, resolver Resolver) options.Option[cfgOpts] {
+	return func(v *cfgOpts) error {
+		if !isScheme(scheme) {
+			return fmt.Errorf("invalid reference scheme '%s'", scheme)
+		}
+
+		if resolver == nil {
+			return errors.New("got nil resolver")
+		}
+
+		if err := WithReferences()(v); err != nil {
+			return err
+		}
+
+		v.resolvers[scheme] = resolver
+		return nil
+	}
+}
+
+// WithReferenceDepth is an option that overrides the maximum depth of references resolved
+// from values of other references. By default, DefaultReferenceDepth is used.
+func WithReferenceDepth(depth int) options.Option[cfgOpts] {
+	return func(v *cfgOpts) error {
+		if depth <= 0 {
+			return fmt.Errorf("reference depth must be positive, got %d", depth)
+		}
+
+		v.referenceDepth = depth
+		return nil
+	}
+}
+
+// resolveReferences resolves references in string fields of config pointed by cfg
+// if resolution is enabled.
+func (o cfgOpts) resolveReferences(ctx context.Context, cfg any) error {
+	if o.resolvers == nil {
+		return nil
+	}
+
+	r := referenceResolver{
+		resolvers: o.resolvers,
+		maxDepth:  o.referenceDepth,
+	}
+	if r.maxDepth == 0 {
+		r.maxDepth = DefaultReferenceDepth
+	}
+
+	if err := r.resolveValue(ctx, reflect.ValueOf(cfg), ""); err != nil {
+		return fmt.Errorf("resolve references: %w", err)
+	}
+
+	return nil
+}
+
+type referenceResolver struct {
+	resolvers map[string]Resolver
+	maxDepth  int
+}
+
+// resolveValue resolves references in strings held by v. Values that can't be set
+// (e.g. unexported fields) are skipped.
+func (r *referenceResolver) resolveValue(ctx context.Context, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+
+		return r.resolveValue(ctx, v.Elem(), path)
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return nil
+		}
+
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := r.resolveValue(ctx, elem, path); err != nil {
+			return err
+		}
+
+		v.Set(elem)
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+
+		s, err := r.resolveString(ctx, v.String(), 0)
+		if err != nil {
+			var refErr *ReferenceError
+			if errors.As(err, &refErr) {
+				refErr.Path = path
+			}
+			return err
+		}
+
+		v.SetString(s)
+	case reflect.Struct:
+		var errs []error
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+
+			fieldPath := path
+			if name := fieldName(field); name != "" {
+				fieldPath = joinPath(path, name)
+			}
+
+			errs = append(errs, r.resolveValue(ctx, v.Field(i), fieldPath))
+		}
+
+		return errors.Join(errs...)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Implements(secretValueType) {
+			return r.resolveValue(ctx, v.Index(0), path)
+		}
+
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, r.resolveValue(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i)))
+		}
+
+		return errors.Join(errs...)
+	case reflect.Map:
+		var (
+			errs   []error
+			keys   []reflect.Value
+			values []reflect.Value
+		)
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values can't be set, so they are resolved in copies.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := r.resolveValue(ctx, elem, fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			keys = append(keys, iter.Key())
+			values = append(values, elem)
+		}
+
+		for i := range keys {
+			v.SetMapIndex(keys[i], values[i])
+		}
+
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// resolveString replaces references in s with their values. Values of references found
+// in config (depth 0) must be well-formed and have registered schemes, while in resolved
+// values (depth > 0) only references in braces with registered schemes are replaced.
+func (r *referenceResolver) resolveString(ctx context.Context, s string, depth int) (string, error) {
+	strict := depth == 0
+
+	// Whole value without braces. Resolved values aren't checked, so a secret like
+	// "file:abc" isn't taken for a reference.
+	if scheme, ref, ok := strings.Cut(s, ":"); strict && ok && !strings.Contains(s, "${") {
+		if resolver, ok := r.resolvers[scheme]; ok {
+			return r.resolve(ctx, s, resolver, ref, depth)
+		}
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		if strict && start > 0 && s[start-1] == '$' {
+			// Escaped "$${".
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		b.WriteString(s[:start])
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			if strict {
+				return "", &ReferenceError{Reference: s[start:], Err: errors.New("unterminated reference")}
+			}
+
+			b.WriteString(s[start:])
+			return b.String(), nil
+		}
+
+		expr := s[start : start+end+1]
+		s = s[start+end+1:]
+
+		scheme, ref, _ := strings.Cut(expr[2:len(expr)-1], ":")
+		resolver, ok := r.resolvers[scheme]
+		if !ok {
+			if strict {
+				return "", &ReferenceError{Reference: expr, Err: fmt.Errorf("unknown scheme '%s'", scheme)}
+			}
+
+			b.WriteString(expr)
+			continue
+		}
+
+		value, err := r.resolve(ctx, expr, resolver, ref, depth)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+	}
+}
+
+// resolve resolves reference expr with resolver and references in its value.
+func (r *referenceResolver) resolve(ctx context.Context, expr string, resolver Resolver, ref string, depth int) (string, error) {
+	if depth >= r.maxDepth {
+		return "", &ReferenceError{
+			Reference: expr,
+			Err:       fmt.Errorf("max depth %d is exceeded, references may be cyclic", r.maxDepth),
+		}
+	}
+
+	value, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", &ReferenceError{Reference: expr, Err: err}
+	}
+
+	value, err = r.resolveString(ctx, value, depth+1)
+	if err != nil {
+		return "", &ReferenceError{Reference: expr, Err: err}
+	}
+
+	return value, nil
+}
+
+// isScheme reports whether s is a valid reference scheme: letters, digits, "+", "-", "." and "_".
+func isScheme(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '+', c == '-', c == '.', c == '_':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// EnvResolver returns resolver of references to environment variables, e.g. "env:DB_PASSWORD".
+// Unset variables are reported with ErrSourceNotFound, set empty variables are resolved to "".
+func EnvResolver() Resolver {
+	return ResolverFunc(func(_ context.Context, ref string) (string, error) {
+		value, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("environment variable '%s' isn't set: %w", ref, ErrSourceNotFound)
+		}
+
+		return value, nil
+	})
+}
+
+// FileResolver returns resolver of references to files, e.g. "file:/run/secrets/db".
+// It's ProviderResolver of FromFile, so a field of JSON file can be referenced too,
+// e.g. "file:/run/secrets/db.json#password".
+func FileResolver() *providerResolver {
+	return ProviderResolver(func(ref string) ConfigProvider {
+		return FromFile(ref)
+	})
+}
+
+// ConsulResolver returns resolver of references to consul kv keys, e.g.
+// "consul:service/db/password" or "consul:service/db#password" for a field of JSON value.
+// Options are passed to FromConsul.
+func ConsulResolver(opts ...ConsulOption) *providerResolver {
+	return ProviderResolver(func(ref string) ConfigProvider {
+		return FromConsul(ref, opts...)
+	})
+}
+
+// VaultResolver returns resolver of references to fields of Vault secrets, e.g.
+// "vault:secret/db#password". Options are passed to FromVault. Providers of secrets are
+// cached, so each secret path logs in once; call Close of the resolver to stop renewal of
+// their tokens.
+func VaultResolver(opts ...VaultOption) *providerResolver {
+	return ProviderResolver(func(ref string) ConfigProvider {
+		return FromVault(ref, opts...)
+	})
+}
+
+type providerResolver struct {
+	newProvider func(ref string) ConfigProvider
+
+	mu        sync.Mutex
+	providers map[string]ConfigProvider
+}
+
+// ProviderResolver returns resolver that resolves references with providers created by
+// newProvider. The part of the reference after the last "#" selects a field of the JSON
+// object provided, e.g. "secret/db#password"; without it the whole data is the value.
+// Trailing newline of the value is trimmed.
+//
+// Providers are created once for every reference without field and reused.
+func ProviderResolver(newProvider func(ref string) ConfigProvider) *providerResolver {
+	return &providerResolver{
+		newProvider: newProvider,
+		providers:   map[string]ConfigProvider{},
+	}
+}
+
+// Resolve implements Resolver.
+func (p *providerResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	ref, field, hasField := cutLast(ref, "#")
+	provider := p.provider(ref)
+
+	r, err := provideConfig(ctx, provider)
+	if err != nil {
+		return "", newProviderError(provider, err)
+	}
+
+	data, err := readAllClose(r)
+	if err != nil {
+		return "", newProviderError(provider, fmt.Errorf("read config: %w", err))
+	}
+
+	if !hasField {
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return "", fmt.Errorf("decode object: %w", err)
+	}
+
+	raw, ok := object[field]
+	if !ok {
+		return "", fmt.Errorf("field '%s' doesn't exist", field)
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+
+	// Numbers, booleans, objects and arrays are kept as JSON.
+	return string(raw), nil
+}
+
+func (p *providerResolver) provider(ref string) ConfigProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	provider, ok := p.providers[ref]
+	if !ok {
+		provider = p.newProvider(ref)
+		p.providers[ref] = provider
+	}
+
+	return provider
+}
+
+// Close closes cached providers that implement io.Closer, e.g. stops renewal of Vault tokens.
+func (p *providerResolver) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for ref, provider := range p.providers {
+		if c, ok := provider.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+		delete(p.providers, ref)
+	}
+
+	return errors.Join(errs...)
+}
+
+// cutLast slices s around the last instance of sep like strings.Cut.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"time"
+)
+
+var (
+	_ ConfigProvider        = &retryProvider{}
+	_ ContextConfigProvider = &retryProvider{}
+	_ SourceNamer           = &retryProvider{}
+	_ FormatHinter          = &retryProvider{}
+)
+
+// DefaultRetryPolicy is the policy used for zero fields of RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     5,
+	InitialInterval: 100 * time.Millisecond,
+	MaxInterval:     5 * time.Second,
+	Multiplier:      2,
+	Jitter:          0.2,
+}
+
+// RetryPolicy defines how WithRetry retries failed calls of a provider.
+// Zero MaxAttempts, InitialInterval, MaxInterval and Multiplier are taken from DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls of the provider including the first one.
+	// Negative value means that the number of attempts isn't limited.
+	MaxAttempts int
+	// MaxElapsed limits the time spent on attempts and delays between them. Zero means no limit.
+	MaxElapsed time.Duration
+	// InitialInterval is the delay after the first failed attempt.
+	InitialInterval time.Duration
+	// MaxInterval is the maximum delay between attempts.
+	MaxInterval time.Duration
+	// Multiplier is the factor the delay grows by after each failed attempt, must be at least 1.
+	Multiplier float64
+	// Jitter randomizes delays by the given fraction in [0, 1]: delay is chosen from
+	// [delay*(1-Jitter), delay*(1+Jitter)].
+	Jitter float64
+	// Retryable reports whether the error of the provider is transient. By default,
+	// DefaultRetryable is used.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryable reports whether err is transient. Errors of missing sources
+// (see ErrSourceNotFound) and errors of context aren't transient, other errors are.
+func DefaultRetryable(err error) bool {
+	return !isSourceNotFound(err) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialInterval == 0 {
+		p.InitialInterval = DefaultRetryPolicy.InitialInterval
+	}
+	if p.MaxInterval == 0 {
+		p.MaxInterval = max(DefaultRetryPolicy.MaxInterval, p.InitialInterval)
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if p.Retryable == nil {
+		p.Retryable = DefaultRetryable
+	}
+
+	return p
+}
+
+func (p RetryPolicy) validate() error {
+	switch {
+	case p.MaxElapsed < 0:
+		return fmt.Errorf("negative max elapsed time %s", p.MaxElapsed)
+	case p.InitialInterval < 0 || p.MaxInterval < p.InitialInterval:
+		return fmt.Errorf("invalid interval bounds [%s, %s]", p.InitialInterval, p.MaxInterval)
+	case p.Multiplier < 1:
+		return fmt.Errorf("multiplier %g is less than 1", p.Multiplier)
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("jitter %g isn't in [0, 1]", p.Jitter)
+	}
+
+	return nil
+}
+
+// delay returns randomized delay after failed attempt with number attempt starting from 1.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := float64(p.InitialInterval)
+	for i := 1; i < attempt && delay < float64(p.MaxInterval); i++ {
+		delay *= p.Multiplier
+	}
+	delay = min(delay, float64(p.MaxInterval))
+
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(delay)
+}
+
+type retryProvider struct {
+	provider ConfigProvider
+	policy   RetryPolicy
+}
+
+// WithRetry returns provider that retries failed calls of provider with exponential backoff
+// and jitter defined by policy. It stops when the error isn't retryable, attempts are over,
+// MaxElapsed is exceeded or context is done (see NewContext), and returns the last error.
+//
+// It can be used as a provider of FallbackProvider and Multi:
+//
+//	cfg, err := config.Multi[MyConfig]().
+//		Add(config.FromFile("config.json")).
+//		Add(config.WithRetry(config.FromConsul("service/config"), config.RetryPolicy{
+//			MaxElapsed: 30 * time.Second,
+//		})).
+//		AllOf()
+func WithRetry(provider ConfigProvider, policy RetryPolicy) *retryProvider {
+	return &retryProvider{
+		provider: provider,
+		policy:   policy.withDefaults(),
+	}
+}
+
+// ProvideConfig implements ConfigProvider.
+func (r *retryProvider) ProvideConfig() (io.Reader, error) {
+	return r.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is propagated to the provider
+// and interrupts delays between attempts. If MaxElapsed is set, an attempt is interrupted
+// when it's exceeded.
+func (r *retryProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	if err := r.policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid retry policy: %w", err)
+	}
+
+	start := time.Now()
+	var deadline time.Time
+	if r.policy.MaxElapsed > 0 {
+		deadline = start.Add(r.policy.MaxElapsed)
+	}
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		rd, err := r.attempt(ctx, deadline)
+		if err == nil {
+			return rd, nil
+		}
+
+		if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+			return nil, errors.Join(ctxErr, err)
+		}
+		if ctx.Err() == nil && !deadline.IsZero() && !time.Now().Before(deadline) {
+			// The attempt was interrupted, so the error of the previous one is reported too.
+			if lastErr != nil && errors.Is(err, context.DeadlineExceeded) {
+				err = errors.Join(err, lastErr)
+			}
+			return nil, fmt.Errorf("give up after %d attempts in %s: %w", attempt, time.Since(start).Round(time.Millisecond), err)
+		}
+		lastErr = err
+		if ctx.Err() != nil || !r.policy.Retryable(err) {
+			return nil, err
+		}
+
+		if r.policy.MaxAttempts > 0 && attempt >= r.policy.MaxAttempts {
+			return nil, fmt.Errorf("give up after %d attempts: %w", attempt, err)
+		}
+
+		delay := r.policy.delay(attempt)
+		if r.policy.MaxElapsed > 0 && time.Since(start)+delay > r.policy.MaxElapsed {
+			return nil, fmt.Errorf("give up after %d attempts in %s: %w", attempt, time.Since(start).Round(time.Millisecond), err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt calls the provider once. If deadline isn't zero, the call is interrupted when
+// it's reached.
+func (r *retryProvider) attempt(ctx context.Context, deadline time.Time) (io.Reader, error) {
+	if deadline.IsZero() {
+		return provideConfig(ctx, r.provider)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	rd, err := provideConfig(ctx, r.provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// Data is read at once, so reading isn't interrupted when the attempt ends.
+	format := formatHint(rd)
+	data, err := readAllClose(rd)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	return &bufferedReader{Reader: bytes.NewReader(data), format: format, src: rd}, nil
+}
+
+// bufferedReader is a reader of data read from src at once. It keeps the format hint of src
+// and commits src when the config is accepted (see decodeConfigs).
+type bufferedReader struct {
+	*bytes.Reader
+	format string
+	src    io.Reader
+}
+
+// FormatHint implements FormatHinter. It returns the hint of src.
+func (r *bufferedReader) FormatHint() string {
+	return r.format
+}
+
+func (r *bufferedReader) commitDecoded() {
+	if c, ok := r.src.(decodedCommitter); ok {
+		c.commitDecoded()
+	}
+}
+
+// Source implements SourceNamer. It returns the name of the wrapped provider.
+func (r *retryProvider) Source() string {
+	return sourceName(r.provider)
+}
+
+// FormatHint implements FormatHinter. It returns the hint of the wrapped provider.
+func (r *retryProvider) FormatHint() string {
+	if hinter, ok := r.provider.(FormatHinter); ok {
+		return hinter.FormatHint()
+	}
+
+	return ""
+}
@@ -0,0 +1,161 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyProvider fails first fails calls with err.
+type flakyProvider struct {
+	fails int32
+	err   error
+	calls atomic.Int32
+}
+
+func (p *flakyProvider) ProvideConfig() (io.Reader, error) {
+	if p.calls.Add(1) <= p.fails {
+		return nil, p.err
+	}
+
+	return strings.NewReader(`{"foo": "bar"}`), nil
+}
+
+// blockingProvider blocks until ctx is done.
+type blockingProvider struct{}
+
+func (p *blockingProvider) ProvideConfig() (io.Reader, error) {
+	return p.ProvideConfigContext(context.Background())
+}
+
+func (p *blockingProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestWithRetry(t *testing.T) {
+	fastPolicy := config.RetryPolicy{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     5 * time.Millisecond,
+		Jitter:          0.5,
+	}
+	errTransient := errors.New("connection refused")
+
+	t.Run("recovers", func(t *testing.T) {
+		provider := &flakyProvider{fails: 3, err: errTransient}
+
+		cfg, err := config.New[testConfig](config.WithRetry(provider, fastPolicy))
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "bar"}, cfg)
+		require.EqualValues(t, 4, provider.calls.Load())
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		provider := &flakyProvider{fails: 10, err: errTransient}
+
+		policy := fastPolicy
+		policy.MaxAttempts = 3
+		_, err := config.New[testConfig](config.WithRetry(provider, policy))
+		require.ErrorIs(t, err, errTransient)
+		require.ErrorContains(t, err, "give up after 3 attempts")
+		require.EqualValues(t, 3, provider.calls.Load())
+	})
+
+	t.Run("max elapsed", func(t *testing.T) {
+		provider := &flakyProvider{fails: 1000, err: errTransient}
+
+		policy := fastPolicy
+		policy.MaxAttempts = -1
+		policy.MaxElapsed = 30 * time.Millisecond
+		start := time.Now()
+		_, err := config.New[testConfig](config.WithRetry(provider, policy))
+		require.ErrorIs(t, err, errTransient)
+		require.Less(t, time.Since(start), time.Second)
+		require.Greater(t, provider.calls.Load(), int32(2))
+	})
+
+	t.Run("max elapsed interrupts attempt", func(t *testing.T) {
+		provider := &blockingProvider{}
+
+		policy := fastPolicy
+		policy.MaxAttempts = -1
+		policy.MaxElapsed = 30 * time.Millisecond
+		start := time.Now()
+		_, err := config.New[testConfig](config.WithRetry(provider, policy))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, "give up after 1 attempts")
+		require.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		missing := config.FromFile(filepath.Join(t.TempDir(), "missing.json"))
+
+		_, err := config.New[testConfig](config.WithRetry(missing, fastPolicy))
+		require.ErrorIs(t, err, os.ErrNotExist)
+		require.NotContains(t, err.Error(), "give up")
+
+		provider := &flakyProvider{fails: 10, err: errTransient}
+		policy := fastPolicy
+		policy.Retryable = func(err error) bool { return false }
+		_, err = config.New[testConfig](config.WithRetry(provider, policy))
+		require.ErrorIs(t, err, errTransient)
+		require.EqualValues(t, 1, provider.calls.Load())
+	})
+
+	t.Run("context", func(t *testing.T) {
+		provider := &flakyProvider{fails: 1000, err: errTransient}
+
+		policy := fastPolicy
+		policy.MaxAttempts = -1
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		_, err := config.NewContext[testConfig](ctx, config.WithRetry(provider, policy))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		for _, policy := range []config.RetryPolicy{
+			{Multiplier: 0.5},
+			{Jitter: 2},
+			{InitialInterval: time.Second, MaxInterval: time.Millisecond},
+			{MaxElapsed: -time.Second},
+		} {
+			_, err := config.New[testConfig](config.WithRetry(okProvider(), policy))
+			require.ErrorContains(t, err, "invalid retry policy")
+		}
+	})
+
+	t.Run("composes", func(t *testing.T) {
+		path := writeFile(t, "config.json", `{"foo": "file"}`)
+		provider := &flakyProvider{fails: 2, err: errTransient}
+
+		cfg, err := config.New[testConfig](config.FallbackProvider(
+			config.WithRetry(&flakyProvider{fails: 10, err: errTransient}, config.RetryPolicy{MaxAttempts: 2, InitialInterval: time.Millisecond}),
+			config.WithRetry(config.FromFile(path), fastPolicy),
+		))
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "file"}, cfg)
+
+		cfg, err = config.Multi[testConfig]().
+			Add(config.FromFile(path)).
+			Add(config.WithRetry(provider, fastPolicy)).
+			AllOf()
+		require.NoError(t, err)
+		require.Equal(t, testConfig{Foo: "bar"}, cfg)
+
+		_, err = config.Multi[testConfig]().
+			AddOptional(config.WithRetry(config.FromFile(filepath.Join(t.TempDir(), "missing.json")), fastPolicy)).
+			AllOf()
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted is the text that secret values are replaced with when config is printed.
+const Redacted = "***"
+
+const secretTag = "secret"
+
+var secretValueType = reflect.TypeOf((*secretValue)(nil)).Elem()
+
+// secretValue is implemented by Secret, so secrets can be found by reflection.
+type secretValue interface {
+	secretValue()
+}
+
+var (
+	_ fmt.Formatter  = Secret[string]{}
+	_ fmt.GoStringer = Secret[string]{}
+	_ slog.LogValuer = Secret[string]{}
+	_ json.Marshaler = Secret[string]{}
+	_ yaml.Marshaler = Secret[string]{}
+)
+
+// Secret holds a secret value of config that isn't shown when the config is printed:
+// fmt, slog and marshaling to JSON, YAML, TOML or text show Redacted. Use Value to get
+// the secret. Secrets are decoded from strings by all decoders of the package, and from
+// any JSON, YAML and TOML values:
+//
+//	type Config struct {
+//		User     string                `json:"user" env:"DB_USER"`
+//		Password config.Secret[string] `json:"password" env:"DB_PASSWORD" validate:"required"`
+//	}
+//
+//	log.Printf("config: %+v", cfg) // config: {User:app Password:***}
+//	db.Connect(cfg.User, cfg.Password.Value())
+//
+// Fields of other types can be marked with `secret:"true"` tag, see Redact.
+type Secret[T any] [1]T
+
+// NewSecret returns Secret holding v.
+func NewSecret[T any](v T) Secret[T] {
+	return Secret[T]{v}
+}
+
+// Value returns the secret value.
+func (s Secret[T]) Value() T {
+	return s[0]
+}
+
+func (Secret[T]) secretValue() {}
+
+// String implements fmt.Stringer.
+func (Secret[T]) String() string {
+	return Redacted
+}
+
+// GoString implements fmt.GoStringer.
+func (Secret[T]) GoString() string {
+	return Redacted
+}
+
+// Format implements fmt.Formatter, so all verbs print Redacted.
+func (Secret[T]) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(Redacted))
+}
+
+// LogValue implements slog.LogValuer.
+func (Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (Secret[T]) MarshalYAML() (any, error) {
+	return Redacted, nil
+}
+
+// MarshalFlag implements flags.Marshaler of cmdline decoder.
+func (Secret[T]) MarshalFlag() (string, error) {
+	return Redacted, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Secret[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &s[0])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Text is parsed like values of
+// `default` tag.
+func (s *Secret[T]) UnmarshalText(text []byte) error {
+	return setFromString(reflect.ValueOf(&s[0]).Elem(), string(text))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *Secret[T]) UnmarshalYAML(node *yaml.Node) error {
+	return node.Decode(&s[0])
+}
+
+// UnmarshalTOML implements toml.Unmarshaler.
+func (s *Secret[T]) UnmarshalTOML(v any) error {
+	if text, ok := v.(string); ok {
+		return s.UnmarshalText([]byte(text))
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.UnmarshalJSON(data)
+}
+
+// UnmarshalFlag implements flags.Unmarshaler of cmdline decoder.
+func (s *Secret[T]) UnmarshalFlag(value string) error {
+	return s.UnmarshalText([]byte(value))
+}
+
+// Redact returns a copy of cfg where values of fields marked with `secret:"true"` tag are
+// replaced: strings (including strings in slices, maps and pointers) with Redacted, values
+// of other types with zero values. Secret values are kept as they redact themselves.
+// Use it to print or log configs:
+//
+//	type Config struct {
+//		User     string `json:"user"`
+//		Password string `json:"password" secret:"true"`
+//	}
+//
+//	slog.Info("config loaded", "config", config.Redact(cfg))
+//
+// Dump, Marshal and String print configs with secrets redacted.
+func Redact[T any](cfg T) T {
+	v := reflect.ValueOf(&cfg).Elem()
+	return redactValue(v, false).Interface().(T)
+}
+
+// redactAny is Redact for configs of unknown types.
+func redactAny(cfg any) any {
+	if cfg == nil {
+		return nil
+	}
+
+	return redactValue(reflect.ValueOf(cfg), false).Interface()
+}
+
+// redactValue returns a copy of v where secret values are redacted. If secret is true,
+// the whole v is secret.
+func redactValue(v reflect.Value, secret bool) reflect.Value {
+	if v.Type().Implements(secretValueType) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if secret {
+			return reflect.ValueOf(Redacted).Convert(v.Type())
+		}
+	case reflect.Struct:
+		if !hasExportedFields(v.Type()) {
+			break
+		}
+
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		redactFields(res, v, secret)
+
+		return res
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.New(v.Type().Elem())
+		res.Elem().Set(redactValue(v.Elem(), secret))
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.New(v.Type()).Elem()
+		res.Set(redactValue(v.Elem(), secret))
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(redactValue(v.Index(i), secret))
+		}
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(redactValue(v.Index(i), secret))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(iter.Key(), redactValue(iter.Value(), secret))
+		}
+		return res
+	}
+
+	if secret {
+		return reflect.Zero(v.Type())
+	}
+
+	return v
+}
+
+// redactFields sets exported fields of addressable struct res to redacted fields of v.
+// Fields promoted from unexported embedded structs are redacted in place, because
+// the embedded structs themselves can't be set.
+func redactFields(res, v reflect.Value, secret bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldSecret := secret || isSecretField(field)
+		if !field.IsExported() {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				redactFields(res.Field(i), v.Field(i), fieldSecret)
+			}
+			continue
+		}
+
+		res.Field(i).Set(redactValue(v.Field(i), fieldSecret))
+	}
+}
+
+// isSecretField reports whether field is marked with `secret:"true"` tag.
+func isSecretField(field reflect.StructField) bool {
+	return field.Tag.Get(secretTag) == "true"
+}
@@ -0,0 +1,232 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testSecretConfig struct {
+	User     string                `json:"user" yaml:"user" toml:"user" ini:"user" env:"TEST_USER" long:"user"`
+	Password config.Secret[string] `json:"password" yaml:"password" toml:"password" ini:"password" env:"TEST_PASSWORD" long:"password"`
+	PIN      config.Secret[int]    `json:"pin" yaml:"pin" toml:"pin" ini:"pin" env:"TEST_PIN" long:"pin"`
+	Token    string                `json:"token" yaml:"token" toml:"token" ini:"token" env:"TEST_TOKEN" long:"token" secret:"true"`
+}
+
+func TestSecret_Decode(t *testing.T) {
+	expected := testSecretConfig{
+		User:     "app",
+		Password: config.NewSecret("p@ss"),
+		PIN:      config.NewSecret(1234),
+		Token:    "t0ken",
+	}
+
+	tests := []struct {
+		name string
+		data string
+		dec  func(io.Reader) config.Decoder
+	}{
+		{
+			name: "json",
+			data: `{"user": "app", "password": "p@ss", "pin": 1234, "token": "t0ken"}`,
+			dec:  func(r io.Reader) config.Decoder { return config.JsonStrictDecoder(r) },
+		},
+		{
+			name: "yaml",
+			data: "user: app\npassword: p@ss\npin: 1234\ntoken: t0ken\n",
+			dec:  func(r io.Reader) config.Decoder { return config.YamlStrictDecoder(r) },
+		},
+		{
+			name: "toml",
+			data: "user = 'app'\npassword = 'p@ss'\npin = 1234\ntoken = 't0ken'\n",
+			dec:  func(r io.Reader) config.Decoder { return config.TomlStrictDecoder(r) },
+		},
+		{
+			name: "ini",
+			data: "user = app\npassword = p@ss\npin = 1234\ntoken = t0ken\n",
+			dec:  func(r io.Reader) config.Decoder { return config.IniStrictDecoder(r) },
+		},
+		{
+			name: "dotenv",
+			data: "TEST_USER=app\nTEST_PASSWORD=p@ss\nTEST_PIN=1234\nTEST_TOKEN=t0ken\n",
+			dec:  func(r io.Reader) config.Decoder { return config.DotenvDecoder(r) },
+		},
+		{
+			name: "cmdline",
+			data: "--user app --password p@ss --pin 1234 --token t0ken",
+			dec:  func(r io.Reader) config.Decoder { return config.CmdlineDecoder(r) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.New[testSecretConfig](config.FromReader(strings.NewReader(tt.data)),
+				config.WithDecoder(tt.dec),
+			)
+			require.NoError(t, err)
+			require.Equal(t, expected, cfg)
+			require.Equal(t, "p@ss", cfg.Password.Value())
+			require.Equal(t, 1234, cfg.PIN.Value())
+		})
+	}
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("TEST_USER", "app")
+		t.Setenv("TEST_PASSWORD", "p@ss")
+		t.Setenv("TEST_PIN", "1234")
+		t.Setenv("TEST_TOKEN", "t0ken")
+
+		cfg, err := config.New[testSecretConfig](config.FromEnv(), config.WithDecoder(config.EnvDecoder))
+		require.NoError(t, err)
+		require.Equal(t, expected, cfg)
+	})
+}
+
+func TestSecret_Format(t *testing.T) {
+	r := require.New(t)
+	cfg := testSecretConfig{User: "app", Password: config.NewSecret("p@ss"), PIN: config.NewSecret(1234)}
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%d"} {
+		out := fmt.Sprintf(verb, cfg.Password)
+		r.Equal(config.Redacted, out, verb)
+	}
+
+	out := fmt.Sprintf("%+v", cfg)
+	r.Contains(out, "Password:***")
+	r.NotContains(out, "p@ss")
+	r.NotContains(out, "1234")
+
+	data, err := json.Marshal(cfg)
+	r.NoError(err)
+	r.JSONEq(`{"user": "app", "password": "***", "pin": "***", "token": ""}`, string(data))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("config", "password", cfg.Password, "config", cfg)
+	logger = slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("config", "password", cfg.Password, "config", cfg)
+	r.NotContains(buf.String(), "p@ss")
+	r.NotContains(buf.String(), "1234")
+	r.Contains(buf.String(), `password=***`)
+}
+
+func TestRedact(t *testing.T) {
+	type nested struct {
+		Keys []string          `json:"keys"`
+		Tags map[string]string `json:"tags"`
+		Port int               `json:"port"`
+	}
+	type cfgType struct {
+		Name    string  `json:"name"`
+		Token   string  `json:"token" secret:"true"`
+		Ref     *string `json:"ref" secret:"true"`
+		Secrets nested  `json:"secrets" secret:"true"`
+		Public  nested  `json:"public"`
+	}
+
+	ref := "ref-value"
+	cfg := cfgType{
+		Name:    "app",
+		Token:   "t0ken",
+		Ref:     &ref,
+		Secrets: nested{Keys: []string{"k1", "k2"}, Tags: map[string]string{"a": "b"}, Port: 1},
+		Public:  nested{Keys: []string{"p"}, Port: 2},
+	}
+
+	redacted := config.Redact(cfg)
+	require.Equal(t, cfgType{
+		Name:    "app",
+		Token:   config.Redacted,
+		Ref:     redacted.Ref,
+		Secrets: nested{Keys: []string{config.Redacted, config.Redacted}, Tags: map[string]string{"a": config.Redacted}},
+		Public:  nested{Keys: []string{"p"}, Port: 2},
+	}, redacted)
+	require.Equal(t, config.Redacted, *redacted.Ref)
+
+	// Original config isn't changed.
+	require.Equal(t, "ref-value", ref)
+	require.Equal(t, []string{"k1", "k2"}, cfg.Secrets.Keys)
+	require.Equal(t, "b", cfg.Secrets.Tags["a"])
+
+	var buf bytes.Buffer
+	require.NoError(t, config.Dump(&buf, cfg))
+	require.NotContains(t, buf.String(), "t0ken")
+	require.Contains(t, buf.String(), "\n  \"token\": \"***\",\n")
+
+	require.JSONEq(t, buf.String(), config.String(&cfg))
+}
+
+type testSecretCreds struct {
+	User     string `json:"user"`
+	Password string `json:"password" secret:"true"`
+}
+
+func TestRedact_Embedded(t *testing.T) {
+	type cfgType struct {
+		testSecretCreds
+		Host string `json:"host"`
+	}
+
+	cfg := cfgType{testSecretCreds: testSecretCreds{User: "app", Password: "hunter2"}, Host: "db"}
+
+	redacted := config.Redact(cfg)
+	require.Equal(t, "app", redacted.User)
+	require.Equal(t, config.Redacted, redacted.Password)
+	require.Equal(t, "hunter2", cfg.Password)
+
+	data, err := json.Marshal(redacted)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"user": "app", "password": "***", "host": "db"}`, string(data))
+	require.NotContains(t, fmt.Sprintf("%+v", redacted), "hunter2")
+}
+
+func TestSecret_Validate(t *testing.T) {
+	type cfgType struct {
+		Password config.Secret[string] `json:"password" validate:"required,min=8"`
+		Token    string                `json:"token" validate:"min=8" secret:"true"`
+	}
+
+	_, err := config.New[cfgType](config.FromReader(strings.NewReader(`{"password": "short", "token": "short"}`)))
+	require.ErrorContains(t, err, "field 'password' violates rule 'min=8'")
+	require.NotContains(t, fmt.Sprintf("%+v", err), "short")
+
+	var valErr *config.ValidationError
+	require.ErrorAs(t, err, &valErr)
+	require.NotContains(t, fmt.Sprint(valErr.Value), "short")
+
+	_, err = config.New[cfgType](config.FromReader(strings.NewReader(`{"token": "long enough"}`)))
+	require.ErrorContains(t, err, "field 'password' violates rule 'required'")
+}
+
+func TestSecret_Multi(t *testing.T) {
+	type cfgType struct {
+		User     string                `json:"user"`
+		Password config.Secret[string] `json:"password" default:"default"`
+		Token    string                `json:"token" secret:"true"`
+	}
+
+	multi := config.Multi[cfgType]().
+		Add(config.FromReader(strings.NewReader(`{"user": "app", "token": "t0ken"}`))).
+		Add(config.FromReader(strings.NewReader(`{"password": "p@ss"}`)))
+
+	cfg, err := multi.AllOf()
+	require.NoError(t, err)
+	require.Equal(t, "app", cfg.User)
+	require.Equal(t, "p@ss", cfg.Password.Value())
+
+	prov, err := multi.Explain()
+	require.NoError(t, err)
+	require.NotContains(t, prov.String(), "p@ss")
+	require.NotContains(t, prov.String(), "t0ken")
+
+	field, ok := prov.Field("token")
+	require.True(t, ok)
+	require.Equal(t, config.Redacted, field.Value)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/MordaTeam/go-toolbox/options"
+)
+
+// UnknownFieldError is returned by strict decoders when the document contains keys that
+// don't match any field of the config.
+type UnknownFieldError struct {
+	// Paths of unknown keys, e.g. "db.timout" or "servers[1].hots".
+	Paths []string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown fields: %s", strings.Join(e.Paths, ", "))
+}
+
+// newUnknownFieldError returns UnknownFieldError with sorted paths or nil if there are no paths.
+func newUnknownFieldError(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	sort.Strings(paths)
+	return &UnknownFieldError{Paths: paths}
+}
+
+// strictDecoder is implemented by decoders that can reject unknown fields, e.g. json.Decoder.
+type strictDecoder interface {
+	DisallowUnknownFields()
+}
+
+// WithStrict is an option that makes decoding fail with UnknownFieldError when the data
+// contains keys that don't match any field of the config, e.g. a typo like "timout".
+//
+// The option is applied to decoders that implement DisallowUnknownFields method: JSON, YAML,
+// TOML and INI decoders of this package and json.Decoder. Other decoders (e.g. EnvDecoder,
+// where unrelated variables are expected) decode as usual.
+func WithStrict() options.Option[cfgOpts] {
+	return func(v *cfgOpts) error {
+		v.strict = true
+		return nil
+	}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unknownJSONPaths returns paths of keys in tree (decoded JSON document) that don't match
+// fields of t. Keys are matched like encoding/json does, including case-insensitive match.
+func unknownJSONPaths(tree any, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) ||
+		reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	var paths []string
+	switch tree := tree.(type) {
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+
+		for i, item := range tree {
+			paths = append(paths, unknownJSONPaths(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Map:
+			for key, value := range tree {
+				paths = append(paths, unknownJSONPaths(value, t.Elem(), fmt.Sprintf("%s[%s]", path, key))...)
+			}
+		case reflect.Struct:
+			fields := map[string]reflect.Type{}
+			collectJSONFields(t, fields)
+
+			for key, value := range tree {
+				fieldType, ok := fields[key]
+				if !ok {
+					fieldType, ok = fields[strings.ToLower(key)]
+				}
+
+				if !ok {
+					paths = append(paths, joinPath(path, key))
+					continue
+				}
+
+				paths = append(paths, unknownJSONPaths(value, fieldType, joinPath(path, key))...)
+			}
+		}
+	}
+
+	return paths
+}
+
+// collectJSONFields collects types of struct fields by json names and lowercased json names.
+func collectJSONFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			collectJSONFields(fieldType, fields)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = field.Type
+		if _, ok := fields[strings.ToLower(name)]; !ok {
+			fields[strings.ToLower(name)] = field.Type
+		}
+	}
+}
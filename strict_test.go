@@ -0,0 +1,147 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testStrictConfig struct {
+	Name     string                  `json:"name" ini:"name"`
+	LogLevel string                  `json:"log_level" ini:"log_level"`
+	DB       testStrictDB            `json:"db" ini:"db"`
+	Servers  []testStrictDB          `json:"servers" ini:"-"`
+	Pools    map[string]testStrictDB `json:"pools" ini:"-"`
+	Started  time.Time               `json:"started" ini:"-"`
+	Extra    map[string]any          `json:"extra" ini:"-"`
+	testStrictInline
+}
+
+type testStrictDB struct {
+	Host    string        `json:"host" ini:"host"`
+	Timeout time.Duration `json:"timeout" ini:"timeout"`
+}
+
+type testStrictInline struct {
+	Region string `json:"region" ini:"region"`
+}
+
+func requireUnknownFields(t *testing.T, err error, paths ...string) {
+	t.Helper()
+
+	var unknownErr *config.UnknownFieldError
+	require.ErrorAs(t, err, &unknownErr)
+	require.Equal(t, paths, unknownErr.Paths)
+}
+
+func TestStrict_Json(t *testing.T) {
+	data := `{
+		"NAME": "svc",
+		"log_level": "debug",
+		"region": "eu",
+		"timout": "5s",
+		"db": {"host": "localhost", "hots": "x"},
+		"servers": [{"host": "a"}, {"port": 1}],
+		"pools": {"main": {"host": "b", "size": 1}},
+		"started": "2025-01-01T00:00:00Z",
+		"extra": {"anything": {"goes": true}}
+	}`
+
+	cfg, err := config.New[testStrictConfig](config.FromReader(strings.NewReader(data)))
+	require.NoError(t, err)
+	require.Equal(t, "svc", cfg.Name)
+
+	_, err = config.New[testStrictConfig](config.FromReader(strings.NewReader(data)), config.WithStrict())
+	requireUnknownFields(t, err, "db.hots", "pools[main].size", "servers[1].port", "timout")
+
+	_, err = config.New[testStrictConfig](
+		config.FromReader(strings.NewReader(data)),
+		config.WithDecoder(config.JsonStrictDecoder),
+	)
+	requireUnknownFields(t, err, "db.hots", "pools[main].size", "servers[1].port", "timout")
+}
+
+func TestStrict_FillAndMulti(t *testing.T) {
+	cfg := testStrictConfig{Name: "svc"}
+	err := config.Fill(&cfg, config.FromReader(strings.NewReader(`{"timout": "5s"}`)), config.WithStrict())
+	requireUnknownFields(t, err, "timout")
+
+	_, err = config.Multi[testStrictConfig]().
+		Add(config.FromReader(strings.NewReader(`{"name": "svc"}`)), config.WithStrict()).
+		Add(config.FromReader(strings.NewReader(`{"db": {"hots": "x"}}`)), config.WithStrict()).
+		AllOf()
+	requireUnknownFields(t, err, "db.hots")
+
+	cfg, err = config.Multi[testStrictConfig]().
+		Add(config.FromReader(strings.NewReader(`{"name": "svc"}`)), config.WithStrict()).
+		Add(config.FromReader(strings.NewReader(`{"db": {"hots": "x"}}`))).
+		AllOf()
+	require.NoError(t, err)
+	require.Equal(t, "svc", cfg.Name)
+}
+
+func TestStrict_Decoders(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		data  string
+		paths []string
+	}{
+		{
+			name:  "yaml",
+			file:  "config.yaml",
+			data:  "name: svc\nlog_level: debug\ntimout: 5s\ndb:\n  hots: x\nservers:\n  - host: a\n  - port: 1\n",
+			paths: []string{"db.hots", "servers[1].port", "timout"},
+		},
+		{
+			name:  "toml",
+			file:  "config.toml",
+			data:  "name = \"svc\"\ntimout = \"5s\"\n[db]\nhots = \"x\"\n[cache]\nsize = 1\n",
+			paths: []string{"cache", "db.hots", "timout"},
+		},
+		{
+			name:  "ini",
+			file:  "config.ini",
+			data:  "name = svc\ntimout = 5s\n[db]\nhost = localhost\nhots = x\n[cache]\nsize = 1\n",
+			paths: []string{"cache", "db.hots", "timout"},
+		},
+		{
+			name: "env",
+			file: "config.env",
+			data: "NAME=svc\nUNRELATED=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.file, tt.data)
+
+			_, err := config.New[testStrictConfig](config.FromFile(path), config.WithAutoDecoder())
+			require.NoError(t, err)
+
+			_, err = config.New[testStrictConfig](config.FromFile(path), config.WithAutoDecoder(), config.WithStrict())
+			if tt.paths == nil {
+				require.NoError(t, err)
+				return
+			}
+			requireUnknownFields(t, err, tt.paths...)
+		})
+	}
+}
+
+func TestStrict_Fallback(t *testing.T) {
+	dec := config.FallbackDecoder(
+		config.DecoderWrap(config.JsonDecoder),
+		config.DecoderWrap(config.YamlDecoder),
+	)
+
+	_, err := config.New[testStrictConfig](
+		config.FromReader(strings.NewReader("name: svc\ntimout: 5s\n")),
+		config.WithDecoder(dec),
+		config.WithStrict(),
+	)
+	requireUnknownFields(t, err, "timout")
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var _ Decoder = &tomlDecoder{}
+
+type tomlDecoder struct {
+	reader io.Reader
+	strict bool
+}
+
+// DisallowUnknownFields causes the decoder to return UnknownFieldError when the document
+// contains keys that don't match any field of the destination.
+func (dec *tomlDecoder) DisallowUnknownFields() {
+	dec.strict = true
+}
+
+// Decode implements Decoder.
+func (dec *tomlDecoder) Decode(v any) error {
+	var doc map[string]any
+	if _, err := toml.NewDecoder(dec.reader).Decode(&doc); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return fmt.Errorf(
+				"parse toml at line %d, column %d: %w",
+				parseErr.Position.Line, parseErr.Position.Col, err,
+			)
+		}
+		return fmt.Errorf("parse toml: %w", err)
+	}
+
+	renameTomlKeys(doc, reflect.TypeOf(v))
+
+	// Document is re-encoded, because toml library can't decode already parsed document.
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+		return fmt.Errorf("encode toml: %w", err)
+	}
+
+	md, err := toml.NewDecoder(&buf).Decode(v)
+	if err != nil {
+		return fmt.Errorf("decode toml: %w", err)
+	}
+
+	if dec.strict {
+		if err := newUnknownFieldError(undecodedTomlPaths(md.Undecoded())); err != nil {
+			return fmt.Errorf("decode toml: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// TomlDecoder returns decoder that decodes TOML into struct with tags.
+// Fields without `toml` tag are matched by the name from `json` tag, so the same struct
+// can be used for JSON and TOML configs.
+// Syntax errors contain line and column of the error.
+// It uses under the hood [BurntSushi/toml] library.
+//
+// [BurntSushi/toml]: https://github.com/BurntSushi/toml
+func TomlDecoder(r io.Reader) *tomlDecoder {
+	return &tomlDecoder{reader: r}
+}
+
+// TomlStrictDecoder returns TomlDecoder that fails on keys that don't match any field.
+func TomlStrictDecoder(r io.Reader) *tomlDecoder {
+	return &tomlDecoder{reader: r, strict: true}
+}
+
+// undecodedTomlPaths returns paths of undecoded keys. Keys nested into another undecoded
+// key are skipped, because the whole table is unknown.
+func undecodedTomlPaths(keys []toml.Key) []string {
+	undecoded := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		undecoded[key.String()] = struct{}{}
+	}
+
+	var paths []string
+	for _, key := range keys {
+		nested := false
+		for i := 1; i < len(key); i++ {
+			if _, ok := undecoded[key[:i].String()]; ok {
+				nested = true
+				break
+			}
+		}
+
+		if !nested {
+			paths = append(paths, key.String())
+		}
+	}
+
+	return paths
+}
+
+var tomlUnmarshalerType = reflect.TypeOf((*toml.Unmarshaler)(nil)).Elem()
+
+// renameTomlKeys renames keys that match `json` tag of struct fields without `toml` tag
+// to the field names, which toml library matches case-insensitively.
+func renameTomlKeys(tree any, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || reflect.PointerTo(t).Implements(tomlUnmarshalerType) {
+		return
+	}
+
+	switch tree := tree.(type) {
+	case []map[string]any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, item := range tree {
+				renameTomlKeys(item, t.Elem())
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, item := range tree {
+				renameTomlKeys(item, t.Elem())
+			}
+		}
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Map:
+			for _, value := range tree {
+				renameTomlKeys(value, t.Elem())
+			}
+		case reflect.Struct:
+			fields := map[string]reflect.Type{}
+			renames := map[string]string{}
+			collectTomlFields(t, fields, renames)
+
+			for key, value := range tree {
+				if _, ok := fields[strings.ToLower(key)]; !ok {
+					if name, ok := renames[key]; ok {
+						delete(tree, key)
+						tree[name] = value
+						key = name
+					}
+				}
+
+				if fieldType, ok := fields[strings.ToLower(key)]; ok {
+					renameTomlKeys(value, fieldType)
+				}
+			}
+		}
+	}
+}
+
+// collectTomlFields collects types of struct fields by lowercased toml names and
+// field names of fields without `toml` tag by their `json` names.
+func collectTomlFields(t reflect.Type, fields map[string]reflect.Type, renames map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tomlTag, hasTomlTag := field.Tag.Lookup("toml")
+		name, _, _ := strings.Cut(tomlTag, ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			collectTomlFields(fieldType, fields, renames)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+
+		if hasTomlTag {
+			continue
+		}
+
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName != "" && jsonName != "-" && !strings.EqualFold(jsonName, name) {
+			renames[jsonName] = name
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type tomlCfg struct {
+	Name     string            `toml:"name"`
+	LogLevel string            `json:"log_level"`
+	Started  time.Time         `json:"started"`
+	Labels   map[string]string `json:"labels"`
+	Servers  []tomlServer      `json:"servers"`
+	DB       *tomlDB           `toml:"database" json:"db"`
+}
+
+type tomlServer struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type tomlDB struct {
+	MaxConns int `json:"max_conns"`
+}
+
+var tomlData = `
+name = "foo"
+log_level = "debug"
+started = 2025-01-01T10:00:00Z
+
+[labels]
+team = "core"
+
+[[servers]]
+host = "localhost"
+port = 8080
+
+[[servers]]
+host = "example.com"
+port = 443
+
+[database]
+max_conns = 10
+`
+
+func TestTomlDecoder(t *testing.T) {
+	expCfg := tomlCfg{
+		Name:     "foo",
+		LogLevel: "debug",
+		Started:  time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+		Labels:   map[string]string{"team": "core"},
+		Servers: []tomlServer{
+			{Host: "localhost", Port: 8080},
+			{Host: "example.com", Port: 443},
+		},
+		DB: &tomlDB{MaxConns: 10},
+	}
+
+	cfg, err := config.New[tomlCfg](
+		config.FromReader(strings.NewReader(tomlData)),
+		config.WithDecoder(config.TomlDecoder),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expCfg, cfg)
+
+	cfg, err = config.New[tomlCfg](
+		config.FromReader(strings.NewReader(tomlData)),
+		config.WithDecoder(config.TomlStrictDecoder),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expCfg, cfg)
+}
+
+func TestTomlDecoder_Strict(t *testing.T) {
+	data := "name = \"foo\"\ntimout = \"5s\"\n"
+
+	cfg, err := config.New[tomlCfg](
+		config.FromReader(strings.NewReader(data)),
+		config.WithDecoder(config.TomlDecoder),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "foo", cfg.Name)
+
+	_, err = config.New[tomlCfg](
+		config.FromReader(strings.NewReader(data)),
+		config.WithDecoder(config.TomlStrictDecoder),
+	)
+	require.ErrorContains(t, err, "timout")
+}
+
+func TestTomlDecoder_SyntaxError(t *testing.T) {
+	_, err := config.New[tomlCfg](
+		config.FromReader(strings.NewReader("name = \"foo\"\nlog_level = debug\n")),
+		config.WithDecoder(config.TomlDecoder),
+	)
+	require.ErrorContains(t, err, "line 2, column 13")
+}
+
+func TestTomlDecoder_Fallback(t *testing.T) {
+	dec := config.FallbackDecoder(
+		config.DecoderWrap(config.TomlDecoder),
+		config.DecoderWrap(config.YamlDecoder),
+	)
+
+	cfg, err := config.New[tomlCfg](
+		config.FromReader(strings.NewReader(tomlData)),
+		config.WithDecoder(dec),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "debug", cfg.LogLevel)
+
+	cfg, err = config.New[tomlCfg](
+		config.FromReader(strings.NewReader("name: foo\nlog_level: debug\n")),
+		config.WithDecoder(dec),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "debug", cfg.LogLevel)
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const validateTag = "validate"
+
+// Validator is implemented by configs that validate themselves.
+// If config T or *T implements it, Validate is called after the config is built.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError describes violation of a rule from `validate` tag.
+type ValidationError struct {
+	// Path to the field, e.g. "db.pool.max". Path consists of names from `json` tag
+	// or field names if the tag is absent.
+	Path string
+	// Rule that is violated, e.g. "min=1".
+	Rule string
+	// Value of the field.
+	Value any
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("field '%s' violates rule '%s'", e.Path, e.Rule)
+}
+
+// validateConfig validates config pointed by cfg with `validate` tags and Validator interface.
+// All violations are joined.
+//
+// Supported rules:
+//
+//	required   value isn't zero
+//	min=N      number is >= N, length of string, slice or map is >= N
+//	max=N      number is <= N, length of string, slice or map is <= N
+//	oneof=a|b  value is one of the listed
+//
+// Bounds of time.Duration fields may be durations, e.g. "min=1s".
+func validateConfig(cfg any) error {
+	err := validateFields(reflect.ValueOf(cfg), "")
+
+	if v, ok := cfg.(Validator); ok {
+		err = errors.Join(err, v.Validate())
+	}
+
+	if err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+
+	return nil
+}
+
+func validateFields(v reflect.Value, path string) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var errs []error
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+
+			fieldPath := path
+			if name := fieldName(field); name != "" {
+				fieldPath = joinPath(path, name)
+			}
+
+			if rules, ok := field.Tag.Lookup(validateTag); ok {
+				errs = append(errs, validateRules(v.Field(i), fieldPath, rules, isSecretField(field))...)
+			}
+
+			errs = append(errs, validateFields(v.Field(i), fieldPath))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, validateFields(v.Index(i), fmt.Sprintf("%s[%d]", path, i)))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			errs = append(errs, validateFields(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key())))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fieldName returns name of the field used in config paths. It's the name from `json` tag,
+// or the field name if the tag is absent. Embedded structs without name in tag are inlined,
+// so their name is empty.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name != "" && name != "-" {
+		return name
+	}
+
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	if field.Anonymous && fieldType.Kind() == reflect.Struct {
+		return ""
+	}
+
+	return field.Name
+}
+
+// validateRules checks value v of the field at path by rules. Values of secret fields
+// aren't exposed in ValidationError.
+func validateRules(v reflect.Value, path, rules string, secret bool) []error {
+	value := v.Interface()
+	if secret {
+		value = Redacted
+	}
+
+	var errs []error
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		ok, err := checkRule(v, rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field '%s': rule '%s': %w", path, rule, err))
+			continue
+		}
+
+		if !ok {
+			errs = append(errs, &ValidationError{Path: path, Rule: rule, Value: value})
+		}
+	}
+
+	return errs
+}
+
+func checkRule(v reflect.Value, rule string) (bool, error) {
+	name, param, _ := strings.Cut(rule, "=")
+	if name == "required" {
+		return !v.IsZero(), nil
+	}
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			// Only required is checked for absent values.
+			return true, nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type().Implements(secretValueType) {
+		// Rules are checked against the value of Secret.
+		return checkRule(v.Index(0), rule)
+	}
+
+	switch name {
+	case "min", "max":
+		actual, ok := measure(v)
+		if !ok {
+			return false, fmt.Errorf("unsupported type %s", v.Type())
+		}
+
+		bound, err := parseBound(v, param)
+		if err != nil {
+			return false, err
+		}
+
+		if name == "min" {
+			return actual >= bound, nil
+		}
+		return actual <= bound, nil
+	case "oneof":
+		actual := fmt.Sprint(v.Interface())
+		for _, allowed := range strings.Split(param, "|") {
+			if actual == allowed {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, errors.New("unknown rule")
+	}
+}
+
+// measure returns the value of number or the length of string, slice, array or map.
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+func parseBound(v reflect.Value, param string) (float64, error) {
+	if v.Type() == durationType {
+		if d, err := time.ParseDuration(param); err == nil {
+			return float64(d), nil
+		}
+	}
+
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bound '%s': %w", param, err)
+	}
+
+	return bound, nil
+}
@@ -0,0 +1,179 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testValidateConfig struct {
+	Name    string                    `json:"name" validate:"required"`
+	Mode    string                    `json:"mode" validate:"oneof=dev|prod"`
+	Timeout time.Duration             `json:"timeout" validate:"min=1s,max=1m"`
+	DB      testValidateDB            `json:"db"`
+	Servers []testValidateServer      `json:"servers" validate:"min=1"`
+	Limits  map[string]testValidateDB `json:"limits"`
+	Backup  *testValidateServer       `json:"backup"`
+	Extra   map[string]string         `json:"extra" validate:"max=1"`
+	Ignored map[string]testValidateDB `json:"-"`
+}
+
+type testValidateDB struct {
+	Pool testValidatePool `json:"pool"`
+}
+
+type testValidatePool struct {
+	Max int `json:"max" validate:"min=1,max=100"`
+}
+
+type testValidateServer struct {
+	Host string `validate:"required"`
+}
+
+type testSelfValidConfig struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+func (c testSelfValidConfig) Validate() error {
+	if c.Min > c.Max {
+		return errors.New("min is greater than max")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		cfg, err := config.New[testValidateConfig](config.FromReader(strings.NewReader(`{
+			"name": "svc",
+			"mode": "prod",
+			"timeout": 5000000000,
+			"db": {"pool": {"max": 10}},
+			"servers": [{"Host": "a"}]
+		}`)))
+		require.NoError(t, err)
+		require.Equal(t, "svc", cfg.Name)
+	})
+
+	t.Run("all violations", func(t *testing.T) {
+		_, err := config.New[testValidateConfig](config.FromReader(strings.NewReader(`{
+			"mode": "test",
+			"timeout": 1000,
+			"db": {"pool": {"max": 0}},
+			"servers": [{"Host": "a"}, {}],
+			"limits": {"x": {"pool": {"max": 1000}}},
+			"backup": {},
+			"extra": {"a": "1", "b": "2"}
+		}`)))
+		require.Error(t, err)
+
+		var paths []string
+		for _, e := range unwrapJoined(err) {
+			var verr *config.ValidationError
+			require.ErrorAs(t, e, &verr)
+			paths = append(paths, verr.Path+" "+verr.Rule)
+		}
+
+		require.ElementsMatch(t, []string{
+			"name required",
+			"mode oneof=dev|prod",
+			"timeout min=1s",
+			"db.pool.max min=1",
+			"servers[1].Host required",
+			"limits[x].pool.max max=100",
+			"backup.Host required",
+			"extra max=1",
+		}, paths)
+	})
+
+	t.Run("validator", func(t *testing.T) {
+		_, err := config.New[testSelfValidConfig](config.FromReader(strings.NewReader(`{"min": 2, "max": 1}`)))
+		require.ErrorContains(t, err, "min is greater than max")
+
+		_, err = config.New[testSelfValidConfig](config.FromReader(strings.NewReader(`{"min": 1, "max": 2}`)))
+		require.NoError(t, err)
+	})
+
+	t.Run("fill validates merged config", func(t *testing.T) {
+		cfg := testSelfValidConfig{Max: 10}
+		err := config.Fill(&cfg, config.FromReader(strings.NewReader(`{"min": 5}`)))
+		require.NoError(t, err)
+		require.Equal(t, testSelfValidConfig{Min: 5, Max: 10}, cfg)
+
+		err = config.Fill(&cfg, config.FromReader(strings.NewReader(`{"max": 1}`)))
+		require.Error(t, err)
+		require.Equal(t, testSelfValidConfig{Min: 5, Max: 10}, cfg, "config isn't changed on error")
+	})
+
+	t.Run("multi", func(t *testing.T) {
+		_, err := config.Multi[testSelfValidConfig]().
+			Add(config.FromReader(strings.NewReader(`{"min": 5}`))).
+			Add(config.FromReader(strings.NewReader(`{"max": 1}`))).
+			AllOf()
+		require.ErrorContains(t, err, "min is greater than max")
+
+		_, err = config.Multi[testSelfValidConfig]().
+			Add(config.FromReader(strings.NewReader(`{"min": 5}`))).
+			OneOf()
+		require.ErrorContains(t, err, "min is greater than max")
+
+		// Invalid configs are skipped like failed configurators.
+		cfg, err := config.Multi[testSelfValidConfig]().
+			Add(config.FromReader(strings.NewReader(`{"min": 5}`))).
+			Add(config.FromReader(strings.NewReader(`{"max": 5}`))).
+			OneOf()
+		require.NoError(t, err)
+		require.Equal(t, testSelfValidConfig{Max: 5}, cfg)
+
+		_, err = config.Multi[testSelfValidConfig]().
+			Add(config.FromReader(strings.NewReader(`{"min": 5}`))).
+			Add(config.FromReader(strings.NewReader(`{"min": 3, "max": 1}`))).
+			OneOf()
+		require.ErrorContains(t, err, "in pos 0")
+		require.ErrorContains(t, err, "in pos 1")
+		require.ErrorContains(t, err, "min is greater than max")
+
+		cfg, err = config.Multi[testSelfValidConfig]().
+			Add(config.FromReader(strings.NewReader(`{"min": 1}`))).
+			Add(config.FromReader(strings.NewReader(`{"max": 5}`))).
+			AllOf()
+		require.NoError(t, err)
+		require.Equal(t, testSelfValidConfig{Min: 1, Max: 5}, cfg)
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		type invalidConfig struct {
+			Name string `json:"name" validate:"unknown"`
+			Port int    `json:"port" validate:"min=abc"`
+		}
+
+		_, err := config.New[invalidConfig](config.FromReader(strings.NewReader(`{}`)))
+		require.ErrorContains(t, err, "field 'name': rule 'unknown': unknown rule")
+		require.ErrorContains(t, err, "field 'port': rule 'min=abc': invalid bound 'abc'")
+	})
+}
+
+// unwrapJoined returns errors joined by errors.Join, unwrapping single wraps on the way.
+func unwrapJoined(err error) []error {
+	for err != nil {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			var errs []error
+			for _, e := range joined.Unwrap() {
+				errs = append(errs, unwrapJoined(e)...)
+			}
+			return errs
+		}
+
+		next := errors.Unwrap(err)
+		if next == nil {
+			return []error{err}
+		}
+		err = next
+	}
+
+	return nil
+}
@@ -0,0 +1,435 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+const (
+	defaultVaultAppRoleMount    = "approle"
+	defaultVaultKubernetesMount = "kubernetes"
+	// DefaultVaultKubernetesTokenPath is the default path of the service account token
+	// used by VaultWithKubernetesAuth.
+	DefaultVaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+var (
+	_ ConfigProvider        = &vaultProvider{}
+	_ ContextConfigProvider = &vaultProvider{}
+	_ SourceNamer           = &vaultProvider{}
+)
+
+type VaultOption func(*vaultOpts) error
+
+type vaultOpts struct {
+	client    *vault.Client
+	address   string
+	token     string
+	namespace string
+	mount     string
+	kvVersion int
+	version   int
+	login     func(ctx context.Context, client *vault.Client) (*vault.Secret, error)
+
+	onRenew func(*vault.RenewOutput)
+	onDone  func(error)
+}
+
+// VaultLeaseHooks are called by the lifetime watcher of the token obtained by an auth method.
+type VaultLeaseHooks struct {
+	// OnRenew is called after the token is renewed.
+	OnRenew func(renewal *vault.RenewOutput)
+	// OnDone is called when the token can't be renewed anymore, e.g. it reached max TTL.
+	// Err is nil if renewal stopped gracefully. The next call of the provider logs in again.
+	OnDone func(err error)
+}
+
+type vaultProvider struct {
+	secretPath string
+	funcOpts   []VaultOption
+
+	mu       sync.Mutex
+	client   *vault.Client
+	opts     vaultOpts
+	loggedIn bool
+	// tokenExpiry is the expiry time of non-renewable token obtained by an auth method.
+	tokenExpiry time.Time
+	watcher     *vault.LifetimeWatcher
+}
+
+// Overrides vault client. By default, the client is created with vault.DefaultConfig,
+// which reads VAULT_ADDR, VAULT_TOKEN and other environment variables.
+func VaultWithClient(client *vault.Client) VaultOption {
+	return func(v *vaultOpts) error {
+		if client == nil {
+			return errors.New("got nil vault client")
+		}
+
+		v.client = client
+		return nil
+	}
+}
+
+// Defines the address of Vault for the default client.
+func VaultWithAddress(address string) VaultOption {
+	return func(v *vaultOpts) error {
+		v.address = address
+		return nil
+	}
+}
+
+// Defines the token used for requests.
+func VaultWithToken(token string) VaultOption {
+	return func(v *vaultOpts) error {
+		if token == "" {
+			return errors.New("empty vault token")
+		}
+
+		v.token = token
+		return nil
+	}
+}
+
+// Defines the namespace of Vault Enterprise used for requests.
+func VaultWithNamespace(namespace string) VaultOption {
+	return func(v *vaultOpts) error {
+		v.namespace = namespace
+		return nil
+	}
+}
+
+// Defines the mount path of the KV secrets engine. By default, it's the first segment
+// of the secret path, e.g. "secret" for "secret/service". If the secret path doesn't start
+// with the mount, it's relative to the mount.
+func VaultWithMount(mount string) VaultOption {
+	return func(v *vaultOpts) error {
+		mount = strings.Trim(mount, "/")
+		if mount == "" {
+			return errors.New("empty vault mount")
+		}
+
+		v.mount = mount
+		return nil
+	}
+}
+
+// Defines the version of the KV secrets engine: 1 or 2. By default, KV v2 is used.
+func VaultWithKVVersion(kvVersion int) VaultOption {
+	return func(v *vaultOpts) error {
+		if kvVersion != 1 && kvVersion != 2 {
+			return fmt.Errorf("unknown kv version %d", kvVersion)
+		}
+
+		v.kvVersion = kvVersion
+		return nil
+	}
+}
+
+// Pins the version of the secret in KV v2. By default, the latest version is read.
+func VaultWithSecretVersion(version int) VaultOption {
+	return func(v *vaultOpts) error {
+		if version <= 0 {
+			return fmt.Errorf("secret version must be positive, got %d", version)
+		}
+
+		v.version = version
+		return nil
+	}
+}
+
+// Logs in with AppRole auth method mounted at mount ("approle" if it's empty).
+func VaultWithAppRoleAuth(mount, roleID, secretID string) VaultOption {
+	if mount == "" {
+		mount = defaultVaultAppRoleMount
+	}
+
+	return vaultWithLogin(mount, map[string]any{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	}, nil)
+}
+
+// Logs in with Kubernetes auth method mounted at mount ("kubernetes" if it's empty)
+// as role. The service account token is read from tokenPath
+// (DefaultVaultKubernetesTokenPath if it's empty) on each login.
+func VaultWithKubernetesAuth(mount, role, tokenPath string) VaultOption {
+	if mount == "" {
+		mount = defaultVaultKubernetesMount
+	}
+	if tokenPath == "" {
+		tokenPath = DefaultVaultKubernetesTokenPath
+	}
+
+	return vaultWithLogin(mount, map[string]any{"role": role}, func(data map[string]any) error {
+		jwt, err := os.ReadFile(tokenPath)
+		if err != nil {
+			return fmt.Errorf("read service account token: %w", err)
+		}
+
+		data["jwt"] = strings.TrimSpace(string(jwt))
+		return nil
+	})
+}
+
+func vaultWithLogin(mount string, data map[string]any, prepare func(data map[string]any) error) VaultOption {
+	return func(v *vaultOpts) error {
+		v.login = func(ctx context.Context, client *vault.Client) (*vault.Secret, error) {
+			loginData := make(map[string]any, len(data)+1)
+			for key, value := range data {
+				loginData[key] = value
+			}
+
+			if prepare != nil {
+				if err := prepare(loginData); err != nil {
+					return nil, err
+				}
+			}
+
+			return client.Logical().WriteWithContext(ctx, "auth/"+strings.Trim(mount, "/")+"/login", loginData)
+		}
+		return nil
+	}
+}
+
+// Defines hooks called on renewal of the token obtained by an auth method. The token is
+// renewed in background until it reaches max TTL or Close is called.
+func VaultWithLeaseHooks(hooks VaultLeaseHooks) VaultOption {
+	return func(v *vaultOpts) error {
+		v.onRenew = hooks.OnRenew
+		v.onDone = hooks.OnDone
+		return nil
+	}
+}
+
+// Returns config provider that provides a secret from Vault KV secrets engine as JSON object,
+// so it can be decoded by the default decoder. The path consists of the mount and the path
+// of the secret, e.g. "secret/service/db". Use VaultWithMount if the mount has several segments.
+//
+// Missing secrets (and deleted versions) are reported with ErrSourceNotFound.
+//
+// By default, the client is created with vault.DefaultConfig and uses VAULT_TOKEN.
+// Use VaultWithToken, VaultWithAppRoleAuth or VaultWithKubernetesAuth to authenticate.
+// Tokens obtained by auth methods are renewed in background, call Close to stop it.
+//
+//	cfg, err := config.New[Secrets](config.FromVault("secret/service",
+//		config.VaultWithKubernetesAuth("", "service", ""),
+//		config.VaultWithSecretVersion(3),
+//	))
+func FromVault(secretPath string, opts ...VaultOption) *vaultProvider {
+	return &vaultProvider{
+		secretPath: strings.Trim(secretPath, "/"),
+		funcOpts:   opts,
+	}
+}
+
+func (p *vaultProvider) lazyInit() error {
+	if p.client != nil {
+		return nil
+	}
+
+	vaultOpts := vaultOpts{kvVersion: 2}
+	for _, option := range p.funcOpts {
+		if option == nil {
+			continue
+		}
+
+		if err := option(&vaultOpts); err != nil {
+			return fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	if vaultOpts.version > 0 && vaultOpts.kvVersion != 2 {
+		return errors.New("secret version is supported only by kv v2")
+	}
+
+	client := vaultOpts.client
+	if client == nil {
+		cfg := vault.DefaultConfig()
+		if vaultOpts.address != "" {
+			cfg.Address = vaultOpts.address
+		}
+
+		var err error
+		client, err = vault.NewClient(cfg)
+		if err != nil {
+			return fmt.Errorf("create vault client: %w", err)
+		}
+	} else {
+		// Client is cloned, so changing the token doesn't affect the passed one.
+		var err error
+		client, err = client.CloneWithHeaders()
+		if err != nil {
+			return fmt.Errorf("clone vault client: %w", err)
+		}
+		client.SetToken(vaultOpts.client.Token())
+	}
+
+	if vaultOpts.token != "" {
+		client.SetToken(vaultOpts.token)
+	}
+	if vaultOpts.namespace != "" {
+		client.SetNamespace(vaultOpts.namespace)
+	}
+
+	p.client = client
+	p.opts = vaultOpts
+	return nil
+}
+
+// Source implements SourceNamer.
+func (p *vaultProvider) Source() string {
+	return "vault:" + p.secretPath
+}
+
+// ProvideConfig implements ConfigProvider.
+func (p *vaultProvider) ProvideConfig() (io.Reader, error) {
+	return p.ProvideConfigContext(context.Background())
+}
+
+// ProvideConfigContext implements ContextConfigProvider. Ctx is passed to requests.
+func (p *vaultProvider) ProvideConfigContext(ctx context.Context) (io.Reader, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.lazyInit(); err != nil {
+		return nil, fmt.Errorf("init lazy: %w", err)
+	}
+
+	if err := p.ensureLogin(ctx); err != nil {
+		return nil, err
+	}
+
+	mount, secretPath := p.opts.mount, p.secretPath
+	if mount == "" {
+		mount, secretPath, _ = strings.Cut(p.secretPath, "/")
+	} else {
+		// The mount is stripped only as whole leading segments, "secrets/app" is relative
+		// to mount "secret".
+		secretPath = strings.Trim(secretPath, "/")
+		if rest, ok := strings.CutPrefix(secretPath, mount+"/"); ok {
+			secretPath = rest
+		}
+	}
+
+	var (
+		secret *vault.KVSecret
+		err    error
+	)
+	switch {
+	case p.opts.kvVersion == 1:
+		secret, err = p.client.KVv1(mount).Get(ctx, secretPath)
+	case p.opts.version > 0:
+		secret, err = p.client.KVv2(mount).GetVersion(ctx, secretPath, p.opts.version)
+	default:
+		secret, err = p.client.KVv2(mount).Get(ctx, secretPath)
+	}
+
+	if errors.Is(err, vault.ErrSecretNotFound) || (err == nil && secret.Data == nil) {
+		return nil, fmt.Errorf("vault get secret '%s': %w", p.secretPath, ErrSourceNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vault get secret '%s': %w", p.secretPath, err)
+	}
+
+	data, err := json.Marshal(secret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("encode secret: %w", err)
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// ensureLogin logs in with the auth method if it's set and the provider isn't logged in.
+func (p *vaultProvider) ensureLogin(ctx context.Context) error {
+	if p.opts.login == nil {
+		return nil
+	}
+	if p.loggedIn && (p.tokenExpiry.IsZero() || time.Now().Before(p.tokenExpiry)) {
+		return nil
+	}
+
+	secret, err := p.opts.login(ctx, p.client)
+	if err != nil {
+		return fmt.Errorf("vault login: %w", err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return errors.New("vault login: no token in response")
+	}
+
+	p.client.SetToken(secret.Auth.ClientToken)
+	p.loggedIn = true
+	p.tokenExpiry = time.Time{}
+
+	if !secret.Auth.Renewable {
+		if secret.Auth.LeaseDuration > 0 {
+			p.tokenExpiry = time.Now().Add(time.Duration(secret.Auth.LeaseDuration) * time.Second)
+		}
+		return nil
+	}
+
+	watcher, err := p.client.NewLifetimeWatcher(&vault.LifetimeWatcherInput{Secret: secret})
+	if err != nil {
+		return fmt.Errorf("vault token watcher: %w", err)
+	}
+
+	p.watcher = watcher
+	go watcher.Start()
+	go p.watchToken(watcher)
+
+	return nil
+}
+
+// watchToken calls hooks on events of the token watcher. When the token can't be renewed
+// anymore, the next call of the provider logs in again.
+func (p *vaultProvider) watchToken(watcher *vault.LifetimeWatcher) {
+	for {
+		select {
+		case err := <-watcher.DoneCh():
+			p.mu.Lock()
+			if p.watcher == watcher {
+				p.loggedIn = false
+				p.watcher = nil
+			}
+			onDone := p.opts.onDone
+			p.mu.Unlock()
+
+			if onDone != nil {
+				onDone(err)
+			}
+			return
+		case renewal := <-watcher.RenewCh():
+			p.mu.Lock()
+			onRenew := p.opts.onRenew
+			p.mu.Unlock()
+
+			if onRenew != nil {
+				onRenew(renewal)
+			}
+		}
+	}
+}
+
+// Close stops renewal of the token obtained by an auth method.
+func (p *vaultProvider) Close() error {
+	p.mu.Lock()
+	watcher := p.watcher
+	p.watcher = nil
+	p.loggedIn = false
+	p.mu.Unlock()
+
+	if watcher != nil {
+		watcher.Stop()
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+package config_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault is a stand-in of Vault HTTP API with KV v1 mount "kv", KV v2 mount "secret",
+// AppRole and Kubernetes auth methods.
+type fakeVault struct {
+	t  testing.TB
+	mu sync.Mutex
+	// v1 holds secrets of KV v1 by path, v2 holds versions of secrets of KV v2 by path.
+	v1 map[string]map[string]any
+	v2 map[string][]map[string]any
+
+	namespace string
+	tokens    map[string]bool
+	logins    int
+	renewals  int
+	leaseTTL  int
+}
+
+func newFakeVault(t testing.TB) (*fakeVault, string) {
+	f := &fakeVault{
+		t:        t,
+		v1:       map[string]map[string]any{},
+		v2:       map[string][]map[string]any{},
+		tokens:   map[string]bool{"root": true},
+		leaseTTL: 3600,
+	}
+
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	return f, srv.URL
+}
+
+func (f *fakeVault) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(f.t, json.NewEncoder(w).Encode(v))
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.namespace != "" && r.Header.Get("X-Vault-Namespace") != f.namespace {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	if strings.HasPrefix(path, "auth/") && strings.HasSuffix(path, "/login") {
+		var body map[string]any
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch {
+		case path == "auth/approle/login" && body["role_id"] == "role" && body["secret_id"] == "secret":
+		case path == "auth/k8s/login" && body["role"] == "service" && body["jwt"] == "jwt-token":
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		f.logins++
+		token := "token-" + strings.Repeat("x", f.logins)
+		f.tokens[token] = true
+		f.writeJSON(w, map[string]any{"auth": map[string]any{
+			"client_token":   token,
+			"renewable":      true,
+			"lease_duration": f.leaseTTL,
+		}})
+		return
+	}
+
+	if !f.tokens[r.Header.Get("X-Vault-Token")] {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case path == "auth/token/renew-self":
+		f.renewals++
+		f.writeJSON(w, map[string]any{"auth": map[string]any{
+			"client_token":   r.Header.Get("X-Vault-Token"),
+			"renewable":      true,
+			"lease_duration": f.leaseTTL,
+		}})
+	case strings.HasPrefix(path, "kv/"):
+		data, ok := f.v1[strings.TrimPrefix(path, "kv/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.writeJSON(w, map[string]any{"data": data})
+	case strings.HasPrefix(path, "secret/data/"):
+		versions := f.v2[strings.TrimPrefix(path, "secret/data/")]
+		version := len(versions)
+		if v := r.URL.Query().Get("version"); v != "" {
+			require.NoError(f.t, json.Unmarshal([]byte(v), &version))
+		}
+		if version == 0 || version > len(versions) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.writeJSON(w, map[string]any{"data": map[string]any{
+			"data":     versions[version-1],
+			"metadata": map[string]any{"version": version},
+		}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type testVaultConfig struct {
+	DB struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	} `json:"db"`
+}
+
+func TestVaultProvider(t *testing.T) {
+	fake, addr := newFakeVault(t)
+	fake.v1["service"] = map[string]any{"db": map[string]any{"user": "v1", "password": "p1"}}
+	fake.v2["service"] = []map[string]any{
+		{"db": map[string]any{"user": "v2", "password": "old"}},
+		{"db": map[string]any{"user": "v2", "password": "new"}},
+	}
+
+	newCfg := func(path string, opts ...config.VaultOption) (testVaultConfig, error) {
+		opts = append([]config.VaultOption{config.VaultWithAddress(addr)}, opts...)
+		return config.New[testVaultConfig](config.FromVault(path, opts...))
+	}
+
+	t.Run("kv v2", func(t *testing.T) {
+		cfg, err := newCfg("secret/service", config.VaultWithToken("root"))
+		require.NoError(t, err)
+		require.Equal(t, "new", cfg.DB.Password)
+
+		cfg, err = newCfg("secret/service", config.VaultWithToken("root"), config.VaultWithSecretVersion(1))
+		require.NoError(t, err)
+		require.Equal(t, "old", cfg.DB.Password)
+
+		cfg, err = newCfg("service", config.VaultWithToken("root"), config.VaultWithMount("secret"))
+		require.NoError(t, err)
+		require.Equal(t, "new", cfg.DB.Password)
+
+		cfg, err = newCfg("secret/service", config.VaultWithToken("root"), config.VaultWithMount("secret"))
+		require.NoError(t, err)
+		require.Equal(t, "new", cfg.DB.Password)
+
+		// Relative path starting with the name of the mount isn't cut.
+		fake.v2["secrets/app"] = []map[string]any{{"db": map[string]any{"user": "app"}}}
+		cfg, err = newCfg("secrets/app", config.VaultWithToken("root"), config.VaultWithMount("secret"))
+		require.NoError(t, err)
+		require.Equal(t, "app", cfg.DB.User)
+	})
+
+	t.Run("kv v1", func(t *testing.T) {
+		cfg, err := newCfg("kv/service", config.VaultWithToken("root"), config.VaultWithKVVersion(1))
+		require.NoError(t, err)
+		require.Equal(t, "v1", cfg.DB.User)
+
+		_, err = newCfg("kv/service", config.VaultWithKVVersion(1), config.VaultWithSecretVersion(1))
+		require.ErrorContains(t, err, "only by kv v2")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := newCfg("secret/missing", config.VaultWithToken("root"))
+		require.ErrorIs(t, err, config.ErrSourceNotFound)
+
+		_, err = newCfg("kv/missing", config.VaultWithToken("root"), config.VaultWithKVVersion(1))
+		require.ErrorIs(t, err, config.ErrSourceNotFound)
+
+		_, err = newCfg("secret/service", config.VaultWithToken("root"), config.VaultWithSecretVersion(5))
+		require.ErrorIs(t, err, config.ErrSourceNotFound)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		_, err := newCfg("secret/service", config.VaultWithToken("wrong"))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, config.ErrSourceNotFound)
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		fake.mu.Lock()
+		fake.namespace = "team"
+		fake.mu.Unlock()
+		defer func() {
+			fake.mu.Lock()
+			fake.namespace = ""
+			fake.mu.Unlock()
+		}()
+
+		_, err := newCfg("secret/service", config.VaultWithToken("root"))
+		require.Error(t, err)
+
+		cfg, err := newCfg("secret/service", config.VaultWithToken("root"), config.VaultWithNamespace("team"))
+		require.NoError(t, err)
+		require.Equal(t, "new", cfg.DB.Password)
+	})
+
+	t.Run("approle", func(t *testing.T) {
+		provider := config.FromVault("secret/service",
+			config.VaultWithAddress(addr),
+			config.VaultWithAppRoleAuth("", "role", "secret"),
+		)
+		t.Cleanup(func() { _ = provider.Close() })
+
+		for range 2 {
+			cfg, err := config.New[testVaultConfig](provider)
+			require.NoError(t, err)
+			require.Equal(t, "new", cfg.DB.Password)
+		}
+
+		fake.mu.Lock()
+		require.Equal(t, 1, fake.logins)
+		fake.mu.Unlock()
+
+		_, err := newCfg("secret/service", config.VaultWithAppRoleAuth("", "role", "wrong"))
+		require.ErrorContains(t, err, "vault login")
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		tokenPath := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenPath, []byte("jwt-token\n"), 0o600))
+
+		provider := config.FromVault("secret/service",
+			config.VaultWithAddress(addr),
+			config.VaultWithKubernetesAuth("k8s", "service", tokenPath),
+		)
+		t.Cleanup(func() { _ = provider.Close() })
+
+		cfg, err := config.New[testVaultConfig](provider)
+		require.NoError(t, err)
+		require.Equal(t, "new", cfg.DB.Password)
+	})
+
+	t.Run("client", func(t *testing.T) {
+		client, err := vault.NewClient(&vault.Config{Address: addr})
+		require.NoError(t, err)
+		client.SetToken("root")
+
+		cfg, err := config.New[testVaultConfig](config.FromVault("secret/service", config.VaultWithClient(client)))
+		require.NoError(t, err)
+		require.Equal(t, "new", cfg.DB.Password)
+
+		_, err = config.New[testVaultConfig](config.FromVault("secret/service", config.VaultWithClient(nil)))
+		require.Error(t, err)
+	})
+}
+
+func TestVaultProvider_LeaseHooks(t *testing.T) {
+	fake, addr := newFakeVault(t)
+	fake.leaseTTL = 1
+	fake.v2["service"] = []map[string]any{{"db": map[string]any{"password": "p"}}}
+
+	renewed := make(chan struct{}, 10)
+	done := make(chan error, 1)
+	provider := config.FromVault("secret/service",
+		config.VaultWithAddress(addr),
+		config.VaultWithAppRoleAuth("", "role", "secret"),
+		config.VaultWithLeaseHooks(config.VaultLeaseHooks{
+			OnRenew: func(*vault.RenewOutput) { renewed <- struct{}{} },
+			OnDone:  func(err error) { done <- err },
+		}),
+	)
+
+	_, err := config.New[testVaultConfig](provider)
+	require.NoError(t, err)
+
+	select {
+	case <-renewed:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "token isn't renewed")
+	}
+
+	require.NoError(t, provider.Close())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "renewal isn't stopped")
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/MordaTeam/go-toolbox/options"
+)
+
+// DefaultWatchInterval is the reload interval used by Watch for providers that
+// don't implement WatchableProvider.
+const DefaultWatchInterval = 30 * time.Second
+
+// WatchableProvider is a ConfigProvider that can notify about changes of its source.
+//
+// Watch returns a channel that receives a value every time the source changes.
+// The channel must be closed when ctx is done.
+type WatchableProvider interface {
+	ConfigProvider
+	Watch(ctx context.Context) (<-chan struct{}, error)
+}
+
+// WithWatchInterval is an option that makes Watch reload config periodically with interval d.
+// By default, providers implementing WatchableProvider are reloaded only on change notifications,
+// others are reloaded every DefaultWatchInterval.
+func WithWatchInterval(d time.Duration) options.Option[cfgOpts] {
+	return func(v *cfgOpts) error {
+		if d <= 0 {
+			return fmt.Errorf("watch interval must be positive, got %s", d)
+		}
+
+		v.watchInterval = d
+		return nil
+	}
+}
+
+// Change describes reloaded config.
+type Change[T any] struct {
+	Old T
+	New T
+}
+
+// Watcher holds the current config and reloads it when the source changes.
+type Watcher[T any] struct {
+	mu  sync.RWMutex
+	cfg T
+
+	changes chan Change[T]
+	errs    chan error
+	done    chan struct{}
+}
+
+// Config returns the current config.
+func (w *Watcher[T]) Config() T {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.cfg
+}
+
+// Changes returns channel that receives old and new config on every reload that changed config.
+// If the receiver doesn't keep up, intermediate changes are dropped, but Config always returns
+// the latest config. The channel is closed when the watcher stops.
+func (w *Watcher[T]) Changes() <-chan Change[T] {
+	return w.changes
+}
+
+// Errors returns channel that receives errors of failed reloads. The last good config is kept.
+// If the receiver doesn't keep up, intermediate errors are dropped.
+// The channel is closed when the watcher stops.
+func (w *Watcher[T]) Errors() <-chan error {
+	return w.errs
+}
+
+// Done returns channel that is closed when the watcher stops.
+func (w *Watcher[T]) Done() <-chan struct{} {
+	return w.done
+}
+
+// Watch creates config T like New does and keeps it up to date until ctx is done.
+//
+// If provider implements WatchableProvider, config is reloaded on its change notifications.
+// Otherwise, config is reloaded every DefaultWatchInterval. Use WithWatchInterval to override it.
+// Failed reloads are reported to Errors and don't change the current config.
+//
+// Example
+//
+//	w, err := config.Watch[MyConfig](ctx, config.FromFile("config.json"))
+//	if err != nil {
+//		//...
+//	}
+//
+//	for change := range w.Changes() {
+//		log.Printf("config changed: %+v -> %+v", change.Old, change.New)
+//	}
+func Watch[T any](ctx context.Context, provider ConfigProvider, opts ...options.Option[cfgOpts]) (*Watcher[T], error) {
+	cfgOpts, err := newCfgOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The watch is stopped if the initial load fails.
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	// Provider is subscribed before the initial load, so changes made meanwhile aren't missed.
+	var notify <-chan struct{}
+	if wp, ok := provider.(WatchableProvider); ok {
+		notify, err = wp.Watch(watchCtx)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("watch provider: %w", err)
+		}
+	} else if cfgOpts.watchInterval == 0 {
+		cfgOpts.watchInterval = DefaultWatchInterval
+	}
+
+	cfg, err := NewContext[T](ctx, provider, opts...)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("create config: %w", err)
+	}
+
+	var tick <-chan time.Time
+	if cfgOpts.watchInterval > 0 {
+		ticker := time.NewTicker(cfgOpts.watchInterval)
+		go func() {
+			<-watchCtx.Done()
+			ticker.Stop()
+		}()
+
+		tick = ticker.C
+	}
+
+	w := &Watcher[T]{
+		cfg:     cfg,
+		changes: make(chan Change[T], 1),
+		errs:    make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer cancel()
+		w.run(watchCtx, notify, tick, func() (T, error) {
+			return NewContext[T](watchCtx, provider, opts...)
+		})
+	}()
+
+	return w, nil
+}
+
+func (w *Watcher[T]) run(ctx context.Context, notify <-chan struct{}, tick <-chan time.Time, reload func() (T, error)) {
+	defer func() {
+		close(w.changes)
+		close(w.errs)
+		close(w.done)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-notify:
+			if !ok {
+				notify = nil
+				if tick == nil {
+					return
+				}
+				continue
+			}
+		case <-tick:
+		}
+
+		cfg, err := reload()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			sendLatest(w.errs, fmt.Errorf("reload config: %w", err))
+			continue
+		}
+
+		w.mu.Lock()
+		old := w.cfg
+		changed := !reflect.DeepEqual(old, cfg)
+		if changed {
+			w.cfg = cfg
+		}
+		w.mu.Unlock()
+
+		if changed {
+			sendLatest(w.changes, Change[T]{Old: old, New: cfg})
+		}
+	}
+}
+
+// sendLatest sends v to ch without blocking. If ch is full, the oldest value is dropped.
+// ch must have a single sender.
+func sendLatest[V any](ch chan V, v V) {
+	select {
+	case ch <- v:
+		return
+	default:
+	}
+
+	select {
+	case <-ch:
+	default:
+	}
+
+	ch <- v
+}
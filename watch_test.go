@@ -0,0 +1,207 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type testWatchConfig struct {
+	Foo string `json:"foo"`
+}
+
+type chanProvider struct {
+	mu     sync.Mutex
+	data   string
+	err    error
+	notify chan struct{}
+}
+
+func (p *chanProvider) ProvideConfig() (io.Reader, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return strings.NewReader(p.data), nil
+}
+
+func (p *chanProvider) Watch(ctx context.Context) (<-chan struct{}, error) {
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.notify:
+				ch <- struct{}{}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (p *chanProvider) set(data string, err error) {
+	p.mu.Lock()
+	p.data, p.err = data, err
+	p.mu.Unlock()
+
+	p.notify <- struct{}{}
+}
+
+func TestWatch_Notify(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := &chanProvider{data: `{"foo": "bar"}`, notify: make(chan struct{})}
+	w, err := config.Watch[testWatchConfig](ctx, p)
+	r.NoError(err)
+	r.Equal(testWatchConfig{Foo: "bar"}, w.Config())
+
+	p.set(`{"foo": "buz"}`, nil)
+	select {
+	case change := <-w.Changes():
+		r.Equal(testWatchConfig{Foo: "bar"}, change.Old)
+		r.Equal(testWatchConfig{Foo: "buz"}, change.New)
+	case <-time.After(time.Second):
+		r.FailNow("no change")
+	}
+	r.Equal(testWatchConfig{Foo: "buz"}, w.Config())
+
+	p.set("", errors.New("something went wrong"))
+	select {
+	case err := <-w.Errors():
+		r.Error(err)
+	case <-time.After(time.Second):
+		r.FailNow("no error")
+	}
+	r.Equal(testWatchConfig{Foo: "buz"}, w.Config())
+
+	cancel()
+	select {
+	case <-w.Done():
+	case <-time.After(time.Second):
+		r.FailNow("watcher wasn't stopped")
+	}
+
+	_, ok := <-w.Changes()
+	r.False(ok)
+}
+
+func TestWatch_Interval(t *testing.T) {
+	r := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data := `{"foo": "bar"}`
+	var mu sync.Mutex
+	p := providerFunc(func() (io.Reader, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return strings.NewReader(data), nil
+	})
+
+	w, err := config.Watch[testWatchConfig](ctx, p, config.WithWatchInterval(10*time.Millisecond))
+	r.NoError(err)
+	r.Equal(testWatchConfig{Foo: "bar"}, w.Config())
+
+	mu.Lock()
+	data = `{"foo": "buz"}`
+	mu.Unlock()
+
+	select {
+	case change := <-w.Changes():
+		r.Equal(testWatchConfig{Foo: "buz"}, change.New)
+	case <-time.After(time.Second):
+		r.FailNow("no change")
+	}
+}
+
+func TestWatch_InitialError(t *testing.T) {
+	_, err := config.Watch[testWatchConfig](context.Background(), &errProvider{})
+	require.Error(t, err)
+
+	_, err = config.Watch[testWatchConfig](context.Background(), okProvider(), config.WithWatchInterval(0))
+	require.Error(t, err)
+}
+
+func TestWatch_ChangeDuringInitialLoad(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := &changingProvider{}
+	w, err := config.Watch[testWatchConfig](ctx, p)
+	require.NoError(t, err)
+	require.Equal(t, testWatchConfig{Foo: "bar"}, w.Config())
+
+	select {
+	case change := <-w.Changes():
+		require.Equal(t, testWatchConfig{Foo: "buz"}, change.New)
+	case <-time.After(time.Second):
+		require.FailNow(t, "change made during the initial load is lost")
+	}
+}
+
+// changingProvider changes its data right after the first read, like a source changed
+// while the config was loaded for the first time.
+type changingProvider struct {
+	mu    sync.Mutex
+	reads int
+	subs  []chan struct{}
+}
+
+func (p *changingProvider) ProvideConfig() (io.Reader, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reads++
+	if p.reads > 1 {
+		return strings.NewReader(`{"foo": "buz"}`), nil
+	}
+
+	for _, sub := range p.subs {
+		select {
+		case sub <- struct{}{}:
+		default:
+		}
+	}
+
+	return strings.NewReader(`{"foo": "bar"}`), nil
+}
+
+func (p *changingProvider) Watch(ctx context.Context) (<-chan struct{}, error) {
+	ch := make(chan struct{}, 1)
+
+	p.mu.Lock()
+	p.subs = append(p.subs, ch)
+	p.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.subs = nil
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+type providerFunc func() (io.Reader, error)
+
+func (f providerFunc) ProvideConfig() (io.Reader, error) {
+	return f()
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var _ Decoder = &yamlDecoder{}
+
+type yamlDecoder struct {
+	reader io.Reader
+	strict bool
+}
+
+// DisallowUnknownFields causes the decoder to return UnknownFieldError when the document
+// contains keys that don't match any field of the destination.
+func (dec *yamlDecoder) DisallowUnknownFields() {
+	dec.strict = true
+}
+
+// Decode implements Decoder.
+func (dec *yamlDecoder) Decode(v any) error {
+	var node yaml.Node
+	if err := yaml.NewDecoder(dec.reader).Decode(&node); err != nil {
+		return fmt.Errorf("parse yaml: %w", err)
+	}
+
+	if dec.strict {
+		if err := newUnknownFieldError(unknownYamlPaths(&node, reflect.TypeOf(v), "")); err != nil {
+			return err
+		}
+	}
+
+	renameYamlKeys(&node, reflect.TypeOf(v))
+
+	if err := node.Decode(v); err != nil {
+		return fmt.Errorf("decode yaml: %w", err)
+	}
+
+	return nil
+}
+
+// YamlDecoder returns decoder that decodes YAML into struct with tags.
+// Fields without `yaml` tag are matched by the name from `json` tag, so the same struct
+// can be used for JSON and YAML configs.
+// It uses under the hood [yaml.v3] library.
+//
+// [yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func YamlDecoder(r io.Reader) *yamlDecoder {
+	return &yamlDecoder{reader: r}
+}
+
+// YamlStrictDecoder returns YamlDecoder that fails on keys that don't match any field.
+func YamlStrictDecoder(r io.Reader) *yamlDecoder {
+	return &yamlDecoder{reader: r, strict: true}
+}
+
+var yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// renameYamlKeys renames mapping keys that match `json` tag of struct fields without `yaml` tag
+// to the names expected by yaml.v3.
+func renameYamlKeys(node *yaml.Node, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		if t.Implements(yamlUnmarshalerType) {
+			return
+		}
+		t = t.Elem()
+	}
+
+	if t == nil || reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			renameYamlKeys(child, t)
+		}
+	case yaml.SequenceNode:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, child := range node.Content {
+				renameYamlKeys(child, t.Elem())
+			}
+		}
+	case yaml.MappingNode:
+		switch t.Kind() {
+		case reflect.Map:
+			for i := 1; i < len(node.Content); i += 2 {
+				renameYamlKeys(node.Content[i], t.Elem())
+			}
+		case reflect.Struct:
+			fields := map[string]reflect.Type{}
+			renames := map[string]string{}
+			collectYamlFields(t, fields, renames)
+
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				if _, ok := fields[key.Value]; !ok {
+					if name, ok := renames[key.Value]; ok {
+						key.Value = name
+					}
+				}
+
+				if fieldType, ok := fields[key.Value]; ok {
+					renameYamlKeys(node.Content[i+1], fieldType)
+				}
+			}
+		}
+	}
+}
+
+// unknownYamlPaths returns paths of mapping keys in node that don't match fields of t.
+// Keys that match `json` tag of fields without `yaml` tag are known too.
+func unknownYamlPaths(node *yaml.Node, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Pointer {
+		if t.Implements(yamlUnmarshalerType) {
+			return nil
+		}
+		t = t.Elem()
+	}
+
+	if reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+		return nil
+	}
+
+	var paths []string
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			paths = append(paths, unknownYamlPaths(child, t, path)...)
+		}
+	case yaml.SequenceNode:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, child := range node.Content {
+				paths = append(paths, unknownYamlPaths(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case yaml.MappingNode:
+		switch t.Kind() {
+		case reflect.Map:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				keyPath := fmt.Sprintf("%s[%s]", path, node.Content[i].Value)
+				paths = append(paths, unknownYamlPaths(node.Content[i+1], t.Elem(), keyPath)...)
+			}
+		case reflect.Struct:
+			fields := map[string]reflect.Type{}
+			renames := map[string]string{}
+			collectYamlFields(t, fields, renames)
+
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i].Value
+				if key == "<<" {
+					continue
+				}
+
+				fieldType, ok := fields[key]
+				if !ok {
+					fieldType, ok = fields[renames[key]]
+				}
+				if !ok {
+					paths = append(paths, joinPath(path, key))
+					continue
+				}
+
+				paths = append(paths, unknownYamlPaths(node.Content[i+1], fieldType, joinPath(path, key))...)
+			}
+		}
+	}
+
+	return paths
+}
+
+// collectYamlFields collects types of struct fields by yaml names and
+// yaml names of fields without `yaml` tag by their `json` names.
+func collectYamlFields(t reflect.Type, fields map[string]reflect.Type, renames map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		yamlTag, hasYamlTag := field.Tag.Lookup("yaml")
+		name, flags, _ := strings.Cut(yamlTag, ",")
+		if name == "-" {
+			continue
+		}
+
+		if strings.Contains(flags, "inline") {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				collectYamlFields(fieldType, fields, renames)
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+
+		if hasYamlTag {
+			continue
+		}
+
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName != "" && jsonName != "-" && jsonName != name {
+			renames[jsonName] = name
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MordaTeam/go-config"
+	"github.com/stretchr/testify/require"
+)
+
+type yamlCfg struct {
+	Name       string            `yaml:"name"`
+	LogLevel   string            `json:"log_level"`
+	Timeout    time.Duration     `json:"timeout"`
+	Labels     map[string]string `json:"labels"`
+	Servers    []yamlServer      `json:"servers"`
+	DB         *yamlDB           `yaml:"database" json:"db"`
+	YamlInline `yaml:",inline"`
+}
+
+type yamlServer struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type yamlDB struct {
+	MaxConns int `json:"max_conns"`
+}
+
+type YamlInline struct {
+	ShutdownGrace string `json:"shutdown_grace"`
+}
+
+var yamlData = `
+name: foo
+log_level: debug
+timeout: 5s
+labels:
+  team: core
+servers:
+  - host: localhost
+    port: 8080
+  - host: example.com
+    port: 443
+database:
+  max_conns: 10
+shutdown_grace: 10s
+`
+
+func TestYamlDecoder(t *testing.T) {
+	expCfg := yamlCfg{
+		Name:     "foo",
+		LogLevel: "debug",
+		Timeout:  5 * time.Second,
+		Labels:   map[string]string{"team": "core"},
+		Servers: []yamlServer{
+			{Host: "localhost", Port: 8080},
+			{Host: "example.com", Port: 443},
+		},
+		DB:         &yamlDB{MaxConns: 10},
+		YamlInline: YamlInline{ShutdownGrace: "10s"},
+	}
+
+	cfg, err := config.New[yamlCfg](
+		config.FromReader(strings.NewReader(yamlData)),
+		config.WithDecoder(config.YamlDecoder),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expCfg, cfg)
+
+	cfg, err = config.New[yamlCfg](
+		config.FromReader(strings.NewReader(yamlData)),
+		config.WithDecoder(config.YamlStrictDecoder),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expCfg, cfg)
+}
+
+func TestYamlDecoder_Strict(t *testing.T) {
+	data := "name: foo\ntimout: 5s\n"
+
+	cfg, err := config.New[yamlCfg](
+		config.FromReader(strings.NewReader(data)),
+		config.WithDecoder(config.YamlDecoder),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "foo", cfg.Name)
+
+	_, err = config.New[yamlCfg](
+		config.FromReader(strings.NewReader(data)),
+		config.WithDecoder(config.YamlStrictDecoder),
+	)
+	require.ErrorContains(t, err, "timout")
+}
+
+func TestYamlDecoder_Invalid(t *testing.T) {
+	_, err := config.New[yamlCfg](
+		config.FromReader(strings.NewReader("name: [foo")),
+		config.WithDecoder(config.YamlDecoder),
+	)
+	require.Error(t, err)
+}